@@ -10,6 +10,13 @@ import (
 
 var DB *sql.DB
 
+// Querier is implemented by both *sql.DB and *sql.Tx, so queries can run in or outside a transaction
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 // Connect establishes a connection to the PostgreSQL database
 func Connect() error {
 	dbURL := os.Getenv("DATABASE_URL")
@@ -37,3 +44,18 @@ func Close() error {
 	}
 	return nil
 }
+
+// WithTx runs fn inside a transaction, committing if it returns nil and rolling back otherwise
+func WithTx(fn func(tx *sql.Tx) error) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
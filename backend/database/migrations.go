@@ -15,7 +15,6 @@ func RunMigrations() error {
 			description TEXT,
 			location VARCHAR(255),
 			image_url TEXT,
-			images TEXT[],
 			amenities TEXT[],
 			max_guests INTEGER DEFAULT 6,
 			bedrooms INTEGER DEFAULT 3,
@@ -73,8 +72,7 @@ func RunMigrations() error {
 			date DATE NOT NULL,
 			source VARCHAR(50),
 			event_uid VARCHAR(255),
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(property_id, date)
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
 
 		// iCal URLs table
@@ -88,6 +86,291 @@ func RunMigrations() error {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
+
+		// Scope seasons and bedroom configs to a property, assigning existing rows to the first property
+		`ALTER TABLE seasons ADD COLUMN IF NOT EXISTS property_id UUID REFERENCES properties(id) ON DELETE CASCADE`,
+		`UPDATE seasons SET property_id = (SELECT id FROM properties ORDER BY created_at ASC LIMIT 1) WHERE property_id IS NULL`,
+		`ALTER TABLE seasons ALTER COLUMN property_id SET NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS seasons_property_id_idx ON seasons (property_id)`,
+		`ALTER TABLE bedroom_configs ADD COLUMN IF NOT EXISTS property_id UUID REFERENCES properties(id) ON DELETE CASCADE`,
+		`UPDATE bedroom_configs SET property_id = (SELECT id FROM properties ORDER BY created_at ASC LIMIT 1) WHERE property_id IS NULL`,
+		`ALTER TABLE bedroom_configs ALTER COLUMN property_id SET NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS bedroom_configs_property_id_idx ON bedroom_configs (property_id)`,
+
+		// Key imported blocks by the feed they came from, and let several sources claim the same date
+		`ALTER TABLE blocked_dates ADD COLUMN IF NOT EXISTS ical_url_id UUID REFERENCES ical_urls(id) ON DELETE CASCADE`,
+		`UPDATE blocked_dates b SET ical_url_id = u.id
+			FROM ical_urls u
+			WHERE b.ical_url_id IS NULL AND b.source <> 'direct'
+			AND u.property_id = b.property_id AND u.source = b.source`,
+		`ALTER TABLE blocked_dates DROP CONSTRAINT IF EXISTS blocked_dates_property_id_date_key`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS blocked_dates_feed_date_idx ON blocked_dates (ical_url_id, date) WHERE ical_url_id IS NOT NULL`,
+		`CREATE INDEX IF NOT EXISTS blocked_dates_property_date_idx ON blocked_dates (property_id, date)`,
+
+		// Block the nights of enquiries confirmed before confirming blocked them
+		`INSERT INTO blocked_dates (property_id, date, source, event_uid, created_at)
+			SELECT e.property_id, night::date, 'direct', e.id::text, CURRENT_TIMESTAMP
+			FROM enquiries e,
+				generate_series(e.check_in::timestamp, (e.check_out - 1)::timestamp, interval '1 day') AS night
+			WHERE e.status = 'confirmed'
+			AND NOT EXISTS (
+				SELECT 1 FROM blocked_dates b
+				WHERE b.source = 'direct' AND b.event_uid = e.id::text AND b.date = night::date
+			)`,
+
+		// Per-feed sync schedule for the background scheduler
+		`ALTER TABLE ical_urls ADD COLUMN IF NOT EXISTS sync_interval_minutes INTEGER NOT NULL DEFAULT 60`,
+		`ALTER TABLE ical_urls ADD COLUMN IF NOT EXISTS next_sync_at TIMESTAMP`,
+		`ALTER TABLE ical_urls ADD COLUMN IF NOT EXISTS consecutive_failures INTEGER NOT NULL DEFAULT 0`,
+
+		// iCal sync history
+		`ALTER TABLE ical_urls ADD COLUMN IF NOT EXISTS last_error TEXT`,
+		`CREATE TABLE IF NOT EXISTS ical_sync_runs (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			ical_url_id UUID NOT NULL REFERENCES ical_urls(id) ON DELETE CASCADE,
+			started_at TIMESTAMP NOT NULL,
+			finished_at TIMESTAMP NOT NULL,
+			outcome VARCHAR(20) NOT NULL,
+			http_status INTEGER,
+			error_message TEXT,
+			events_parsed INTEGER NOT NULL DEFAULT 0,
+			events_skipped INTEGER NOT NULL DEFAULT 0,
+			dates_added INTEGER NOT NULL DEFAULT 0,
+			dates_removed INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS ical_sync_runs_url_started_idx ON ical_sync_runs (ical_url_id, started_at DESC)`,
+
+		// Validators for conditional iCal fetches. content_synced_at keeps its time zone because it is
+		// compared with time.Now() in Go.
+		`ALTER TABLE ical_urls ADD COLUMN IF NOT EXISTS etag TEXT`,
+		`ALTER TABLE ical_urls ADD COLUMN IF NOT EXISTS last_modified TEXT`,
+		`ALTER TABLE ical_urls ADD COLUMN IF NOT EXISTS content_synced_at TIMESTAMPTZ`,
+
+		// Secret token for the public iCal export feed of each property
+		`ALTER TABLE properties ADD COLUMN IF NOT EXISTS ical_export_token VARCHAR(64)`,
+
+		// Property status; existing properties stay visible to guests
+		`ALTER TABLE properties ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published'`,
+		`CREATE INDEX IF NOT EXISTS properties_status_idx ON properties (status)`,
+
+		// Property photos, replacing the properties.images array
+		`CREATE TABLE IF NOT EXISTS property_images (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+			storage_key TEXT,
+			external_url TEXT,
+			content_type VARCHAR(50),
+			width INTEGER,
+			height INTEGER,
+			size_bytes BIGINT,
+			caption TEXT NOT NULL DEFAULT '',
+			alt_text TEXT NOT NULL DEFAULT '',
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			CHECK (storage_key IS NOT NULL OR external_url IS NOT NULL)
+		)`,
+		`CREATE INDEX IF NOT EXISTS property_images_property_order_idx ON property_images (property_id, sort_order)`,
+		`DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'properties' AND column_name = 'images') THEN
+				INSERT INTO property_images (property_id, external_url, sort_order)
+				SELECT p.id, i.url, i.position - 1
+				FROM properties p, unnest(p.images) WITH ORDINALITY AS i(url, position)
+				WHERE i.url <> '';
+				ALTER TABLE properties DROP COLUMN images;
+			END IF;
+		END $$`,
+
+		// Seasons limited to a range of years, and one-off prices for single dates or ranges
+		`ALTER TABLE seasons ADD COLUMN IF NOT EXISTS start_year INTEGER`,
+		`ALTER TABLE seasons ADD COLUMN IF NOT EXISTS end_year INTEGER`,
+		`CREATE TABLE IF NOT EXISTS date_price_overrides (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			start_date DATE NOT NULL,
+			end_date DATE NOT NULL,
+			daily_price DECIMAL(10,2) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			CHECK (start_date <= end_date)
+		)`,
+		`CREATE INDEX IF NOT EXISTS date_price_overrides_property_dates_idx ON date_price_overrides (property_id, start_date, end_date)`,
+
+		// Explicit season priority, seeded from the old rule that the highest price wins
+		`DO $$
+		BEGIN
+			IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'seasons' AND column_name = 'priority') THEN
+				ALTER TABLE seasons ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+				UPDATE seasons s SET priority = r.priority
+				FROM (SELECT id, dense_rank() OVER (PARTITION BY property_id ORDER BY daily_price) AS priority FROM seasons WHERE NOT is_default) r
+				WHERE s.id = r.id;
+			END IF;
+		END $$`,
+
+		// One default season per property, keeping the oldest where there are several
+		`UPDATE seasons SET is_default = false
+			WHERE is_default AND id NOT IN (
+				SELECT DISTINCT ON (property_id) id FROM seasons WHERE is_default ORDER BY property_id, created_at
+			)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS seasons_one_default_idx ON seasons (property_id) WHERE is_default`,
+
+		// Minimum and maximum stay rules on seasons and date ranges
+		`ALTER TABLE seasons ADD COLUMN IF NOT EXISTS min_nights INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE seasons ADD COLUMN IF NOT EXISTS max_nights INTEGER NOT NULL DEFAULT 0`,
+		`CREATE TABLE IF NOT EXISTS stay_rules (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			start_date DATE,
+			end_date DATE,
+			min_nights INTEGER NOT NULL DEFAULT 0,
+			max_nights INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			CHECK ((start_date IS NULL) = (end_date IS NULL) AND (start_date IS NULL OR start_date <= end_date))
+		)`,
+		`CREATE INDEX IF NOT EXISTS stay_rules_property_id_idx ON stay_rules (property_id)`,
+
+		// Closed to arrival and departure weekdays (0 = Sunday) on seasons and stay rules
+		`ALTER TABLE seasons ADD COLUMN IF NOT EXISTS closed_to_arrival INTEGER[] NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE seasons ADD COLUMN IF NOT EXISTS closed_to_departure INTEGER[] NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE stay_rules ADD COLUMN IF NOT EXISTS closed_to_arrival INTEGER[] NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE stay_rules ADD COLUMN IF NOT EXISTS closed_to_departure INTEGER[] NOT NULL DEFAULT '{}'`,
+
+		// Day-of-week price adjustments per season
+		`CREATE TABLE IF NOT EXISTS season_weekday_adjustments (
+			season_id UUID NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+			weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+			adjustment_type VARCHAR(10) NOT NULL CHECK (adjustment_type IN ('price', 'percent')),
+			amount DECIMAL(10,2) NOT NULL,
+			PRIMARY KEY (season_id, weekday)
+		)`,
+
+		// Length-of-stay discounts
+		`CREATE TABLE IF NOT EXISTS stay_discounts (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			min_nights INTEGER NOT NULL CHECK (min_nights > 1),
+			discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percent', 'amount')),
+			amount DECIMAL(10,2) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (property_id, min_nights)
+		)`,
+
+		// Fees and taxes per property, and the quote an enquiry was priced with
+		`CREATE TABLE IF NOT EXISTS property_fees (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			fee_type VARCHAR(30) NOT NULL CHECK (fee_type IN ('per_stay', 'per_night', 'per_guest', 'per_guest_per_night', 'percent')),
+			amount DECIMAL(10,2) NOT NULL,
+			included_guests INTEGER NOT NULL DEFAULT 0,
+			taxable BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS property_fees_property_id_idx ON property_fees (property_id)`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS quote JSONB`,
+
+		// Currency of every property's prices; enquiries keep the currency they were priced in.
+		// Prices are widened so totals in currencies such as IDR fit.
+		`ALTER TABLE properties ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD'`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD'`,
+		`ALTER TABLE seasons ALTER COLUMN daily_price TYPE DECIMAL(15,2)`,
+		`ALTER TABLE bedroom_configs ALTER COLUMN price_add TYPE DECIMAL(15,2)`,
+		`ALTER TABLE date_price_overrides ALTER COLUMN daily_price TYPE DECIMAL(15,2)`,
+		`ALTER TABLE season_weekday_adjustments ALTER COLUMN amount TYPE DECIMAL(15,2)`,
+		`ALTER TABLE stay_discounts ALTER COLUMN amount TYPE DECIMAL(15,2)`,
+		`ALTER TABLE property_fees ALTER COLUMN amount TYPE DECIMAL(15,2)`,
+		`ALTER TABLE enquiries ALTER COLUMN total_price TYPE DECIMAL(15,2)`,
+
+		// Promo codes and the enquiries that used them
+		`CREATE TABLE IF NOT EXISTS promo_codes (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+			code VARCHAR(50) NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percent', 'amount')),
+			amount DECIMAL(15,2) NOT NULL,
+			valid_from DATE,
+			valid_until DATE,
+			stay_from DATE,
+			stay_until DATE,
+			min_nights INTEGER NOT NULL DEFAULT 0,
+			max_uses INTEGER NOT NULL DEFAULT 0,
+			max_uses_per_email INTEGER NOT NULL DEFAULT 0,
+			active BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (property_id, code)
+		)`,
+		`CREATE TABLE IF NOT EXISTS promo_redemptions (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			promo_code_id UUID NOT NULL REFERENCES promo_codes(id) ON DELETE CASCADE,
+			enquiry_id UUID NOT NULL UNIQUE REFERENCES enquiries(id) ON DELETE CASCADE,
+			email VARCHAR(255) NOT NULL,
+			amount DECIMAL(15,2) NOT NULL,
+			currency CHAR(3) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS promo_redemptions_promo_code_id_idx ON promo_redemptions (promo_code_id)`,
+
+		// Dynamic pricing rules applied after season pricing
+		`CREATE TABLE IF NOT EXISTS pricing_rules (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+			name VARCHAR(100) NOT NULL,
+			rule_type VARCHAR(20) NOT NULL CHECK (rule_type IN ('lead_time', 'occupancy', 'gap')),
+			min_days_out INTEGER,
+			max_days_out INTEGER,
+			min_occupancy INTEGER,
+			max_occupancy INTEGER,
+			window_days INTEGER NOT NULL DEFAULT 30,
+			max_gap_nights INTEGER NOT NULL DEFAULT 0,
+			percent DECIMAL(7,2) NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS pricing_rules_property_id_idx ON pricing_rules (property_id)`,
+
+		// Saved price quotes, binding until they expire. expires_at keeps its time zone, unlike the other
+		// timestamps, because it is compared with time.Now() in Go rather than in SQL.
+		`CREATE TABLE IF NOT EXISTS quotes (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			property_id UUID NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+			check_in DATE NOT NULL,
+			check_out DATE NOT NULL,
+			bedroom_config_id UUID REFERENCES bedroom_configs(id) ON DELETE CASCADE,
+			quote JSONB NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL,
+			enquiry_id UUID UNIQUE REFERENCES enquiries(id) ON DELETE CASCADE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Admin users table
+		`CREATE TABLE IF NOT EXISTS admin_users (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			email VARCHAR(255) NOT NULL,
+			name VARCHAR(255) NOT NULL DEFAULT '',
+			role VARCHAR(20) NOT NULL DEFAULT 'staff',
+			password_hash TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS admin_users_email_idx ON admin_users (LOWER(email))`,
+
+		// Admin sessions table
+		`CREATE TABLE IF NOT EXISTS admin_sessions (
+			token_hash VARCHAR(64) PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES admin_users(id) ON DELETE CASCADE,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
 	}
 
 	for _, migration := range migrations {
@@ -117,25 +400,44 @@ func SeedData() error {
 	}
 
 	// Insert default property
-	_, err = DB.Exec(`
-		INSERT INTO properties (name, tagline, description, location, image_url, images, amenities, max_guests, bedrooms, bathrooms)
+	var propertyID string
+	err = DB.QueryRow(`
+		INSERT INTO properties (name, tagline, description, location, image_url, amenities, max_guests, bedrooms, bathrooms)
 		VALUES (
 			'Villa Arama Riverside',
 			'Luxury Riverside Retreat in Bali',
 			'Experience tranquility at Villa Arama Riverside, a stunning luxury villa nestled along the banks of a pristine river in Bali. This exclusive retreat offers an unparalleled blend of traditional Balinese architecture and modern luxury amenities. Wake up to the gentle sounds of flowing water, enjoy breathtaking views from your private terrace, and immerse yourself in the natural beauty that surrounds this exceptional property.',
 			'Ubud, Bali, Indonesia',
 			'https://images.unsplash.com/photo-1582719478250-c89cae4dc85b?w=1200',
-			ARRAY['https://images.unsplash.com/photo-1582719478250-c89cae4dc85b?w=800', 'https://images.unsplash.com/photo-1571896349842-33c89424de2d?w=800', 'https://images.unsplash.com/photo-1578683010236-d716f9a3f461?w=800', 'https://images.unsplash.com/photo-1584132967334-10e028bd69f7?w=800'],
 			ARRAY['Private Pool', 'River View', 'Air Conditioning', 'Free WiFi', 'Full Kitchen', 'Daily Housekeeping', 'Garden', 'BBQ Area', 'Yoga Deck', 'Parking'],
 			8,
 			3,
 			3
 		)
-	`)
+		RETURNING id
+	`).Scan(&propertyID)
 	if err != nil {
 		return err
 	}
 
+	// Insert default photos
+	images := []string{
+		"https://images.unsplash.com/photo-1582719478250-c89cae4dc85b?w=800",
+		"https://images.unsplash.com/photo-1571896349842-33c89424de2d?w=800",
+		"https://images.unsplash.com/photo-1578683010236-d716f9a3f461?w=800",
+		"https://images.unsplash.com/photo-1584132967334-10e028bd69f7?w=800",
+	}
+
+	for i, url := range images {
+		_, err = DB.Exec(`
+			INSERT INTO property_images (property_id, external_url, sort_order)
+			VALUES ($1, $2, $3)
+		`, propertyID, url, i)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Insert default seasons
 	seasons := []struct {
 		name       string
@@ -151,9 +453,9 @@ func SeedData() error {
 
 	for _, s := range seasons {
 		_, err = DB.Exec(`
-			INSERT INTO seasons (name, start_date, end_date, daily_price, is_default)
-			VALUES ($1, $2, $3, $4, $5)
-		`, s.name, s.startDate, s.endDate, s.dailyPrice, s.isDefault)
+			INSERT INTO seasons (property_id, name, start_date, end_date, daily_price, is_default)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, propertyID, s.name, s.startDate, s.endDate, s.dailyPrice, s.isDefault)
 		if err != nil {
 			return err
 		}
@@ -174,9 +476,9 @@ func SeedData() error {
 
 	for _, c := range configs {
 		_, err = DB.Exec(`
-			INSERT INTO bedroom_configs (name, description, price_add, max_guests, is_default)
-			VALUES ($1, $2, $3, $4, $5)
-		`, c.name, c.description, c.priceAdd, c.maxGuests, c.isDefault)
+			INSERT INTO bedroom_configs (property_id, name, description, price_add, max_guests, is_default)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, propertyID, c.name, c.description, c.priceAdd, c.maxGuests, c.isDefault)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"strings"
+	"villa-arama-riverside/middleware"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAdminUsers returns all admin users
+func GetAdminUsers(c *fiber.Ctx) error {
+	users, err := repository.GetAllAdminUsers()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch admin users"})
+	}
+
+	return c.JSON(users)
+}
+
+// CreateAdminUser creates a new admin user
+func CreateAdminUser(c *fiber.Ctx) error {
+	var req models.CreateAdminUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" || req.Password == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Email and password are required"})
+	}
+
+	if !models.IsValidRole(req.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid role. Must be owner, manager, or staff"})
+	}
+
+	existing, err := repository.GetAdminUserByEmail(req.Email)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create admin user"})
+	}
+	if existing != nil {
+		return c.Status(409).JSON(fiber.Map{"error": "An admin user with this email already exists"})
+	}
+
+	hash, err := services.HashPassword(req.Password)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	user, err := repository.CreateAdminUser(req.Email, req.Name, req.Role, hash)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create admin user"})
+	}
+
+	return c.Status(201).JSON(user)
+}
+
+// UpdateAdminUser updates an admin user's name, role or password
+func UpdateAdminUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdateAdminUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if !models.IsValidRole(req.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid role. Must be owner, manager, or staff"})
+	}
+
+	user, err := repository.GetAdminUserByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update admin user"})
+	}
+	if user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Admin user not found"})
+	}
+
+	if user.Role == models.RoleOwner && req.Role != models.RoleOwner {
+		if status, msg := checkNotLastOwner(); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+	}
+
+	var hash string
+	if req.Password != "" {
+		hash, err = services.HashPassword(req.Password)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	user, err = repository.UpdateAdminUser(id, req.Name, req.Role, hash)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update admin user"})
+	}
+
+	// A password change signs the user out everywhere
+	if hash != "" {
+		if err := repository.DeleteAdminSessionsByUserID(id); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to revoke sessions"})
+		}
+	}
+
+	return c.JSON(user)
+}
+
+// DeleteAdminUser deletes an admin user
+func DeleteAdminUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if current := middleware.CurrentUser(c); current != nil && current.ID == id {
+		return c.Status(400).JSON(fiber.Map{"error": "You cannot delete your own account"})
+	}
+
+	user, err := repository.GetAdminUserByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete admin user"})
+	}
+	if user == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Admin user not found"})
+	}
+
+	if user.Role == models.RoleOwner {
+		if status, msg := checkNotLastOwner(); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+	}
+
+	if err := repository.DeleteAdminUser(id); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete admin user"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Admin user deleted successfully"})
+}
+
+// checkNotLastOwner returns an error status and message if there is only one owner left
+func checkNotLastOwner() (int, string) {
+	owners, err := repository.CountAdminUsersByRole(models.RoleOwner)
+	if err != nil {
+		return 500, "Failed to check remaining owners"
+	}
+	if owners <= 1 {
+		return 400, "At least one owner account is required"
+	}
+	return 0, ""
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+	"villa-arama-riverside/middleware"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Login authenticates an admin user and starts a session
+func Login(c *fiber.Ctx) error {
+	var req models.LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.Email == "" || req.Password == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Email and password are required"})
+	}
+
+	token, user, err := services.Login(req.Email, req.Password)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		return c.Status(401).JSON(fiber.Map{"error": "Invalid email or password"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to log in"})
+	}
+
+	expiresAt := time.Now().Add(services.SessionTTL)
+	c.Cookie(&fiber.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    token,
+		Path:     "/api",
+		Expires:  expiresAt,
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.JSON(fiber.Map{
+		"token":      token,
+		"expires_at": expiresAt,
+		"user":       user,
+	})
+}
+
+// Logout ends the current admin session
+func Logout(c *fiber.Ctx) error {
+	if err := services.Logout(middleware.SessionToken(c)); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to log out"})
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    "",
+		Path:     "/api",
+		Expires:  time.Unix(0, 0),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.JSON(fiber.Map{"message": "Logged out successfully"})
+}
+
+// GetCurrentUser returns the logged-in admin user
+func GetCurrentUser(c *fiber.Ctx) error {
+	return c.JSON(middleware.CurrentUser(c))
+}
@@ -3,13 +3,20 @@ package handlers
 import (
 	"villa-arama-riverside/models"
 	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// GetBedroomConfigs returns all bedroom configurations
+// GetBedroomConfigs returns all bedroom configs, optionally filtered by the property_id query parameter
 func GetBedroomConfigs(c *fiber.Ctx) error {
-	configs, err := repository.GetAllBedroomConfigs()
+	var configs []models.BedroomConfig
+	var err error
+	if propertyID := c.Query("property_id"); propertyID != "" {
+		configs, err = repository.GetBedroomConfigsByPropertyID(propertyID)
+	} else {
+		configs, err = repository.GetAllBedroomConfigs()
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch bedroom configs"})
 	}
@@ -24,8 +31,19 @@ func CreateBedroomConfig(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	if req.Name == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	if req.PropertyID == "" || req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name and property_id are required"})
+	}
+
+	property, err := repository.GetPropertyByID(req.PropertyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+	if err := services.ValidatePrice(property.ID, req.PriceAdd); err != nil {
+		return validationErrorResponse(c, err)
 	}
 
 	config, err := repository.CreateBedroomConfig(req)
@@ -45,7 +63,18 @@ func UpdateBedroomConfig(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	config, err := repository.UpdateBedroomConfig(id, req)
+	config, err := repository.GetBedroomConfigByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch bedroom config"})
+	}
+	if config == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Bedroom config not found"})
+	}
+	if err := services.ValidatePrice(config.PropertyID, req.PriceAdd); err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	config, err = repository.UpdateBedroomConfig(id, req)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update bedroom config"})
 	}
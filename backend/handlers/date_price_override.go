@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetDatePriceOverrides returns date price overrides, optionally filtered by the property_id query parameter
+func GetDatePriceOverrides(c *fiber.Ctx) error {
+	var overrides []models.DatePriceOverride
+	var err error
+	if propertyID := c.Query("property_id"); propertyID != "" {
+		overrides, err = repository.GetDatePriceOverridesByPropertyID(propertyID)
+	} else {
+		overrides, err = repository.GetAllDatePriceOverrides()
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch price overrides"})
+	}
+
+	return c.JSON(overrides)
+}
+
+// CreateDatePriceOverride creates a price for a single date or a date range
+func CreateDatePriceOverride(c *fiber.Ctx) error {
+	var req models.CreateDatePriceOverrideRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.PropertyID == "" || req.Name == "" || req.StartDate == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name, start_date, and property_id are required"})
+	}
+	if req.EndDate == "" {
+		req.EndDate = req.StartDate
+	}
+
+	property, err := repository.GetPropertyByID(req.PropertyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	err = services.ValidateDatePriceOverride(models.DatePriceOverride{
+		PropertyID: property.ID,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		DailyPrice: req.DailyPrice,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	override, err := repository.CreateDatePriceOverride(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create price override"})
+	}
+
+	return c.Status(201).JSON(override)
+}
+
+// UpdateDatePriceOverride updates an existing date price override
+func UpdateDatePriceOverride(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdateDatePriceOverrideRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.Name == "" || req.StartDate == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name and start_date are required"})
+	}
+	if req.EndDate == "" {
+		req.EndDate = req.StartDate
+	}
+
+	override, err := repository.GetDatePriceOverrideByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch price override"})
+	}
+	if override == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Price override not found"})
+	}
+
+	err = services.ValidateDatePriceOverride(models.DatePriceOverride{
+		ID:         id,
+		PropertyID: override.PropertyID,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		DailyPrice: req.DailyPrice,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	override, err = repository.UpdateDatePriceOverride(id, req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update price override"})
+	}
+	if override == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Price override not found"})
+	}
+
+	return c.JSON(override)
+}
+
+// DeleteDatePriceOverride deletes a date price override
+func DeleteDatePriceOverride(c *fiber.Ctx) error {
+	if err := repository.DeleteDatePriceOverride(c.Params("id")); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete price override"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Price override deleted successfully"})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"time"
 	"villa-arama-riverside/models"
@@ -9,6 +10,7 @@ import (
 	"villa-arama-riverside/services"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 // CreateEnquiry creates a new booking enquiry
@@ -19,8 +21,8 @@ func CreateEnquiry(c *fiber.Ctx) error {
 	}
 
 	// Validate required fields
-	if req.Name == "" || req.Email == "" || req.CheckIn == "" || req.CheckOut == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Name, email, check_in, and check_out are required"})
+	if req.PropertyID == "" || req.Name == "" || req.Email == "" || req.CheckIn == "" || req.CheckOut == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name, email, check_in, check_out, and property_id are required"})
 	}
 
 	// Parse dates
@@ -34,16 +36,42 @@ func CreateEnquiry(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid check_out date format"})
 	}
 
-	// Calculate total price
-	totalPrice, _, err := services.CalculatePricing(req.PropertyID, checkIn, checkOut, req.BedroomConfigID)
+	if req.QuoteID != "" {
+		if _, err := uuid.Parse(req.QuoteID); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid quote_id"})
+		}
+	}
+
+	property, err := repository.GetPropertyByID(req.PropertyID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to calculate pricing"})
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil || property.Status != models.PropertyStatusPublished {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
 	}
 
-	// Create enquiry
-	enquiry, err := repository.CreateEnquiry(req, totalPrice)
+	// Validate dates and guest capacity, loading the bedroom config once for validation and pricing
+	bedroomConfig, err := services.GetBedroomConfig(property.ID, req.BedroomConfigID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to create enquiry"})
+		return stayErrorResponse(c, err)
+	}
+	if err := services.ValidateStay(property, checkIn, checkOut, req.Guests, bedroomConfig); err != nil {
+		return stayErrorResponse(c, err)
+	}
+
+	// Quote the stay with its discounts, fees and taxes, unless it uses a saved quote
+	var quote *models.PriceQuote
+	if req.QuoteID == "" {
+		quote, err = services.CalculatePricing(property, checkIn, checkOut, bedroomConfig, req.Guests, req.PromoCode)
+		if err != nil {
+			return stayErrorResponse(c, err)
+		}
+	}
+
+	// Create enquiry, rejecting nights that are already taken, promo codes past their limits and unusable quotes
+	enquiry, err := services.CreateEnquiry(req, quote)
+	if err != nil {
+		return stayErrorResponse(c, err)
 	}
 
 	// Send email notification (non-blocking)
@@ -51,7 +79,7 @@ func CreateEnquiry(c *fiber.Ctx) error {
 		if err := services.SendEnquiryNotification(
 			req.Name, req.Email, req.Phone,
 			req.CheckIn, req.CheckOut, req.Message,
-			req.Guests, totalPrice,
+			req.Guests, enquiry.TotalPrice,
 		); err != nil {
 			fmt.Printf("Failed to send email notification: %v\n", err)
 		}
@@ -84,13 +112,21 @@ func UpdateEnquiryStatus(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid status. Must be pending, confirmed, or cancelled"})
 	}
 
-	enquiry, err := repository.UpdateEnquiryStatus(id, req.Status)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update enquiry status"})
+	enquiry, err := services.UpdateEnquiryStatus(id, req.Status)
+	if errors.Is(err, services.ErrEnquiryNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Enquiry not found"})
 	}
 
-	if enquiry == nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Enquiry not found"})
+	var unavailableErr *services.UnavailableError
+	if errors.As(err, &unavailableErr) {
+		return c.Status(409).JSON(fiber.Map{
+			"error":             "Cannot confirm: some nights are already blocked",
+			"conflicting_dates": unavailableErr.Nights,
+		})
+	}
+
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update enquiry status"})
 	}
 
 	return c.JSON(enquiry)
@@ -109,7 +145,7 @@ func ExportEnquiries(c *fiber.Ctx) error {
 	writer := csv.NewWriter(c.Response().BodyWriter())
 
 	// Write header
-	writer.Write([]string{"ID", "Name", "Email", "Phone", "Check-In", "Check-Out", "Guests", "Total Price", "Status", "Created At"})
+	writer.Write([]string{"ID", "Name", "Email", "Phone", "Check-In", "Check-Out", "Guests", "Total Price", "Currency", "Status", "Created At"})
 
 	// Write rows
 	for _, e := range enquiries {
@@ -121,7 +157,8 @@ func ExportEnquiries(c *fiber.Ctx) error {
 			e.CheckIn,
 			e.CheckOut,
 			fmt.Sprintf("%d", e.Guests),
-			fmt.Sprintf("%.2f", e.TotalPrice),
+			e.TotalPrice.Decimal().String(),
+			e.TotalPrice.Currency,
 			e.Status,
 			e.CreatedAt.Format("2006-01-02 15:04:05"),
 		})
@@ -130,3 +167,23 @@ func ExportEnquiries(c *fiber.Ctx) error {
 	writer.Flush()
 	return nil
 }
+
+// stayErrorResponse maps booking errors from the services package to HTTP responses
+func stayErrorResponse(c *fiber.Ctx, err error) error {
+	var validationErr *services.ValidationError
+	var unavailableErr *services.UnavailableError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+	case errors.As(err, &unavailableErr):
+		return c.Status(409).JSON(fiber.Map{
+			"error":             "Some of the requested nights are not available",
+			"conflicting_dates": unavailableErr.Nights,
+		})
+	case errors.Is(err, services.ErrBedroomConfigNotFound):
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid bedroom_config_id for this property"})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create enquiry"})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"villa-arama-riverside/models"
 	"villa-arama-riverside/repository"
 	"villa-arama-riverside/services"
@@ -29,17 +30,49 @@ func AddICalURL(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "URL and property_id are required"})
 	}
 
+	if req.SyncIntervalMinutes == 0 {
+		req.SyncIntervalMinutes = models.DefaultICalSyncIntervalMinutes
+	}
+	if req.SyncIntervalMinutes < models.MinICalSyncIntervalMinutes {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("sync_interval_minutes must be at least %d", models.MinICalSyncIntervalMinutes)})
+	}
+
 	icalURL, err := repository.CreateICalURL(req)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to add iCal URL"})
 	}
 
-	// Trigger initial sync
-	go services.SyncICalFeed(icalURL.ID, req.PropertyID, req.URL, req.Source)
+	// New feeds are due immediately; wake the scheduler for the initial sync
+	services.WakeICalScheduler()
 
 	return c.Status(201).JSON(icalURL)
 }
 
+// UpdateICalURL changes how often an iCal URL is synced
+func UpdateICalURL(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdateICalURLRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.SyncIntervalMinutes < models.MinICalSyncIntervalMinutes {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("sync_interval_minutes must be at least %d", models.MinICalSyncIntervalMinutes)})
+	}
+
+	icalURL, err := repository.UpdateICalURLInterval(id, req.SyncIntervalMinutes)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update iCal URL"})
+	}
+
+	if icalURL == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "iCal URL not found"})
+	}
+
+	return c.JSON(icalURL)
+}
+
 // DeleteICalURL deletes an iCal URL
 func DeleteICalURL(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -51,17 +84,39 @@ func DeleteICalURL(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"message": "iCal URL deleted successfully"})
 }
 
-// SyncICalFeeds triggers a sync of all iCal feeds
-func SyncICalFeeds(c *fiber.Ctx) error {
-	urls, err := repository.GetAllICalURLs()
+// GetICalSyncHistory returns the recent sync runs of an iCal URL
+func GetICalSyncHistory(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	icalURL, err := repository.GetICalURLByID(id)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch iCal URLs"})
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch iCal URL"})
+	}
+	if icalURL == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "iCal URL not found"})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 200 {
+		limit = 50
 	}
 
-	syncCount := 0
-	for _, url := range urls {
-		go services.SyncICalFeed(url.ID, url.PropertyID, url.URL, url.Source)
-		syncCount++
+	runs, err := repository.GetICalSyncRuns(id, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch sync history"})
+	}
+
+	return c.JSON(fiber.Map{
+		"ical_url": icalURL,
+		"runs":     runs,
+	})
+}
+
+// SyncICalFeeds triggers a sync of all iCal feeds through the background scheduler
+func SyncICalFeeds(c *fiber.Ctx) error {
+	syncCount, err := services.TriggerICalSync()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to trigger iCal sync"})
 	}
 
 	return c.JSON(fiber.Map{
@@ -69,3 +124,88 @@ func SyncICalFeeds(c *fiber.Ctx) error {
 		"count":   syncCount,
 	})
 }
+
+// ExportPropertyCalendar serves the unavailable nights of a property as an iCal feed for OTAs
+func ExportPropertyCalendar(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	ok, err := services.CheckICalExportToken(id, c.Query("token"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify calendar token"})
+	}
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "Calendar not found"})
+	}
+
+	property, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Calendar not found"})
+	}
+
+	calendar, err := services.BuildICalExport(property)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to build calendar"})
+	}
+
+	c.Set("Content-Type", "text/calendar; charset=utf-8")
+	c.Set("Content-Disposition", "inline; filename=calendar.ics")
+	c.Set("Cache-Control", "no-cache")
+	return c.SendString(calendar)
+}
+
+// GetICalExportURL returns the secret iCal export URL of a property, to paste into OTA dashboards
+func GetICalExportURL(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	property, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	token, err := repository.GetICalExportToken(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch calendar token"})
+	}
+	if token == "" {
+		return c.Status(404).JSON(fiber.Map{"error": "Property has no calendar token yet; rotate it to create one"})
+	}
+
+	return c.JSON(fiber.Map{
+		"property_id": id,
+		"url":         icalExportURL(c, id, token),
+	})
+}
+
+// RotateICalExportToken replaces the iCal export token of a property, invalidating the old URL
+func RotateICalExportToken(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	property, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	token, err := services.RotateICalExportToken(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to rotate calendar token"})
+	}
+
+	return c.JSON(fiber.Map{
+		"property_id": id,
+		"url":         icalExportURL(c, id, token),
+	})
+}
+
+// icalExportURL builds the public export feed URL of a property
+func icalExportURL(c *fiber.Ctx, propertyID, token string) string {
+	return fmt.Sprintf("%s/api/properties/%s/calendar.ics?token=%s", c.BaseURL(), propertyID, token)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetPricingRules returns pricing rules, optionally filtered by the property_id query parameter
+func GetPricingRules(c *fiber.Ctx) error {
+	var rules []models.PricingRule
+	var err error
+	if propertyID := c.Query("property_id"); propertyID != "" {
+		rules, err = repository.GetPricingRulesByPropertyID(propertyID)
+	} else {
+		rules, err = repository.GetAllPricingRules()
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch pricing rules"})
+	}
+
+	return c.JSON(rules)
+}
+
+// CreatePricingRule creates a pricing rule for a property
+func CreatePricingRule(c *fiber.Ctx) error {
+	var req models.CreatePricingRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.PropertyID == "" || req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name and property_id are required"})
+	}
+	if req.WindowDays == 0 {
+		req.WindowDays = models.DefaultOccupancyWindowDays
+	}
+
+	property, err := repository.GetPropertyByID(req.PropertyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	err = services.ValidatePricingRule(models.PricingRule{
+		PropertyID:   req.PropertyID,
+		Type:         req.Type,
+		MinDaysOut:   req.MinDaysOut,
+		MaxDaysOut:   req.MaxDaysOut,
+		MinOccupancy: req.MinOccupancy,
+		MaxOccupancy: req.MaxOccupancy,
+		WindowDays:   req.WindowDays,
+		MaxGapNights: req.MaxGapNights,
+		Percent:      req.Percent,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	rule, err := repository.CreatePricingRule(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create pricing rule"})
+	}
+
+	return c.Status(201).JSON(rule)
+}
+
+// UpdatePricingRule updates an existing pricing rule
+func UpdatePricingRule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdatePricingRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	rule, err := repository.GetPricingRuleByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch pricing rule"})
+	}
+	if rule == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Pricing rule not found"})
+	}
+
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	}
+	if req.WindowDays == 0 {
+		req.WindowDays = models.DefaultOccupancyWindowDays
+	}
+	err = services.ValidatePricingRule(models.PricingRule{
+		ID:           id,
+		PropertyID:   rule.PropertyID,
+		Type:         req.Type,
+		MinDaysOut:   req.MinDaysOut,
+		MaxDaysOut:   req.MaxDaysOut,
+		MinOccupancy: req.MinOccupancy,
+		MaxOccupancy: req.MaxOccupancy,
+		WindowDays:   req.WindowDays,
+		MaxGapNights: req.MaxGapNights,
+		Percent:      req.Percent,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	rule, err = repository.UpdatePricingRule(id, req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update pricing rule"})
+	}
+
+	return c.JSON(rule)
+}
+
+// DeletePricingRule deletes a pricing rule
+func DeletePricingRule(c *fiber.Ctx) error {
+	if err := repository.DeletePricingRule(c.Params("id")); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete pricing rule"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Pricing rule deleted successfully"})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetPromoCodes returns promo codes with their redemption counts, optionally filtered by the property_id query parameter
+func GetPromoCodes(c *fiber.Ctx) error {
+	var codes []models.PromoCode
+	var err error
+	if propertyID := c.Query("property_id"); propertyID != "" {
+		codes, err = repository.GetPromoCodesByPropertyID(propertyID)
+	} else {
+		codes, err = repository.GetAllPromoCodes()
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch promo codes"})
+	}
+
+	return c.JSON(codes)
+}
+
+// CreatePromoCode creates a promo code for a property
+func CreatePromoCode(c *fiber.Ctx) error {
+	var req models.CreatePromoCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	req.Code = services.NormalizePromoCode(req.Code)
+	if req.PropertyID == "" || req.Code == "" || req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Code, name, and property_id are required"})
+	}
+
+	property, err := repository.GetPropertyByID(req.PropertyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	err = services.ValidatePromoCode(models.PromoCode{
+		PropertyID:      req.PropertyID,
+		Code:            req.Code,
+		Type:            req.Type,
+		Amount:          req.Amount,
+		ValidFrom:       req.ValidFrom,
+		ValidUntil:      req.ValidUntil,
+		StayFrom:        req.StayFrom,
+		StayUntil:       req.StayUntil,
+		MinNights:       req.MinNights,
+		MaxUses:         req.MaxUses,
+		MaxUsesPerEmail: req.MaxUsesPerEmail,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	promo, err := repository.CreatePromoCode(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create promo code"})
+	}
+
+	return c.Status(201).JSON(promo)
+}
+
+// UpdatePromoCode updates an existing promo code
+func UpdatePromoCode(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdatePromoCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	promo, err := repository.GetPromoCodeByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch promo code"})
+	}
+	if promo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Promo code not found"})
+	}
+
+	req.Code = services.NormalizePromoCode(req.Code)
+	if req.Code == "" || req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Code and name are required"})
+	}
+	err = services.ValidatePromoCode(models.PromoCode{
+		ID:              id,
+		PropertyID:      promo.PropertyID,
+		Code:            req.Code,
+		Type:            req.Type,
+		Amount:          req.Amount,
+		ValidFrom:       req.ValidFrom,
+		ValidUntil:      req.ValidUntil,
+		StayFrom:        req.StayFrom,
+		StayUntil:       req.StayUntil,
+		MinNights:       req.MinNights,
+		MaxUses:         req.MaxUses,
+		MaxUsesPerEmail: req.MaxUsesPerEmail,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	promo, err = repository.UpdatePromoCode(id, req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update promo code"})
+	}
+
+	return c.JSON(promo)
+}
+
+// DeletePromoCode deletes a promo code. Enquiries that used it keep it in their quote.
+func DeletePromoCode(c *fiber.Ctx) error {
+	if err := repository.DeletePromoCode(c.Params("id")); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete promo code"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Promo code deleted successfully"})
+}
+
+// GetPromoRedemptions returns the enquiries that used a promo code
+func GetPromoRedemptions(c *fiber.Ctx) error {
+	redemptions, err := repository.GetPromoRedemptions(c.Params("id"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch redemptions"})
+	}
+
+	return c.JSON(redemptions)
+}
@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"errors"
 	"time"
+	"villa-arama-riverside/models"
 	"villa-arama-riverside/repository"
 	"villa-arama-riverside/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// GetProperties returns all properties
+// GetProperties returns the published properties
 func GetProperties(c *fiber.Ctx) error {
-	properties, err := repository.GetAllProperties()
+	properties, err := repository.GetPublishedProperties()
+	if err == nil {
+		err = services.AttachPropertyImages(properties)
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch properties"})
 	}
@@ -18,7 +23,7 @@ func GetProperties(c *fiber.Ctx) error {
 	return c.JSON(properties)
 }
 
-// GetProperty returns a single property by ID
+// GetProperty returns a single published property by ID
 func GetProperty(c *fiber.Ctx) error {
 	id := c.Params("id")
 	property, err := repository.GetPropertyByID(id)
@@ -26,13 +31,106 @@ func GetProperty(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
 	}
 
+	if property == nil || property.Status != models.PropertyStatusPublished {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	return propertyWithImages(c, *property)
+}
+
+// GetAdminProperties returns all properties, including drafts and archived ones
+func GetAdminProperties(c *fiber.Ctx) error {
+	properties, err := repository.GetAllProperties()
+	if err == nil {
+		err = services.AttachPropertyImages(properties)
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch properties"})
+	}
+
+	return c.JSON(properties)
+}
+
+// GetAdminProperty returns a single property by ID, whatever its status
+func GetAdminProperty(c *fiber.Ctx) error {
+	property, err := repository.GetPropertyByID(c.Params("id"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
 	if property == nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
 	}
 
+	return propertyWithImages(c, *property)
+}
+
+// propertyWithImages responds with a property and its images
+func propertyWithImages(c *fiber.Ctx, property models.Property) error {
+	properties := []models.Property{property}
+	if err := services.AttachPropertyImages(properties); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property images"})
+	}
+
+	return c.JSON(properties[0])
+}
+
+// CreateProperty creates a new property
+func CreateProperty(c *fiber.Ctx) error {
+	var req models.CreatePropertyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	property, err := services.CreateProperty(req)
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create property"})
+	}
+
+	return c.Status(201).JSON(property)
+}
+
+// UpdateProperty updates the given fields of a property
+func UpdateProperty(c *fiber.Ctx) error {
+	var req models.UpdatePropertyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	property, err := services.UpdateProperty(c.Params("id"), req)
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+	case errors.Is(err, services.ErrPropertyNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	case errors.Is(err, services.ErrPropertyHasPrices):
+		return c.Status(409).JSON(fiber.Map{"error": "Property has prices in its currency; remove them before changing the currency"})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update property"})
+	}
+
 	return c.JSON(property)
 }
 
+// DeleteProperty deletes a property that has no enquiries
+func DeleteProperty(c *fiber.Ctx) error {
+	err := services.DeleteProperty(c.Params("id"))
+	switch {
+	case errors.Is(err, services.ErrPropertyNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	case errors.Is(err, services.ErrPropertyHasEnquiries):
+		return c.Status(409).JSON(fiber.Map{"error": "Property has enquiries; archive it instead"})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete property"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Property deleted successfully"})
+}
+
 // GetPropertyPricing returns pricing for a property
 func GetPropertyPricing(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -41,6 +139,14 @@ func GetPropertyPricing(c *fiber.Ctx) error {
 	checkOutStr := c.Query("check_out")
 	bedroomConfigID := c.Query("bedroom_config_id")
 
+	property, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil || property.Status != models.PropertyStatusPublished {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
 	// If date range is provided, calculate total
 	if checkInStr != "" && checkOutStr != "" {
 		checkIn, err := time.Parse("2006-01-02", checkInStr)
@@ -53,32 +159,65 @@ func GetPropertyPricing(c *fiber.Ctx) error {
 			return c.Status(400).JSON(fiber.Map{"error": "Invalid check_out date format"})
 		}
 
-		totalPrice, breakdown, err := services.CalculatePricing(id, checkIn, checkOut, bedroomConfigID)
+		if !checkOut.After(checkIn) {
+			return c.Status(400).JSON(fiber.Map{"error": "check_out must be after check_in"})
+		}
+
+		guests := c.QueryInt("guests", 1)
+		if guests < 1 {
+			return c.Status(400).JSON(fiber.Map{"error": "guests must be at least 1"})
+		}
+
+		bedroomConfig, err := services.GetBedroomConfig(property.ID, bedroomConfigID)
+		if errors.Is(err, services.ErrBedroomConfigNotFound) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid bedroom_config_id for this property"})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to calculate pricing"})
+		}
+
+		quote, err := services.CalculatePricing(property, checkIn, checkOut, bedroomConfig, guests, c.Query("promo_code"))
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+		}
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to calculate pricing"})
 		}
 
 		return c.JSON(fiber.Map{
-			"property_id": id,
-			"check_in":    checkInStr,
-			"check_out":   checkOutStr,
-			"nights":      len(breakdown),
-			"total_price": totalPrice,
-			"breakdown":   breakdown,
+			"property_id":    id,
+			"check_in":       checkInStr,
+			"check_out":      checkOutStr,
+			"currency":       quote.Currency,
+			"nights":         quote.Nights,
+			"guests":         quote.Guests,
+			"subtotal":       quote.Subtotal,
+			"discount":       quote.Discount,
+			"discount_name":  quote.DiscountName,
+			"promo_code":     quote.PromoCode,
+			"promo_discount": quote.PromoDiscount,
+			"fees":           quote.Fees,
+			"taxes":          quote.Taxes,
+			"total_price":    quote.TotalPrice,
+			"lines":          quote.Lines,
+			"breakdown":      quote.Breakdown,
 		})
 	}
 
 	// Single date pricing
 	date := time.Now()
 	if dateStr != "" {
-		var err error
 		date, err = time.Parse("2006-01-02", dateStr)
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "Invalid date format"})
 		}
 	}
 
-	pricing, err := services.GetPricingForDate(id, date, bedroomConfigID)
+	pricing, err := services.GetPricingForDate(property, date, bedroomConfigID)
+	if errors.Is(err, services.ErrBedroomConfigNotFound) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid bedroom_config_id for this property"})
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to get pricing"})
 	}
@@ -86,23 +225,101 @@ func GetPropertyPricing(c *fiber.Ctx) error {
 	return c.JSON(pricing)
 }
 
-// GetPropertyAvailability returns blocked dates for a property
+// GetPropertyBedroomConfigs returns the bedroom configurations of a published property
+func GetPropertyBedroomConfigs(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	property, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil || property.Status != models.PropertyStatusPublished {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	configs, err := repository.GetBedroomConfigsByPropertyID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch bedroom configs"})
+	}
+
+	return c.JSON(configs)
+}
+
+// GetPropertyAvailability returns blocked dates and stay length limits for a published property
 func GetPropertyAvailability(c *fiber.Ctx) error {
 	id := c.Params("id")
 
+	property, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil || property.Status != models.PropertyStatusPublished {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
 	blockedDates, err := repository.GetBlockedDates(id)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch availability"})
 	}
 
-	// Extract just the dates
+	// Extract just the dates, once each even when several sources block them
 	var dates []string
+	seen := map[string]bool{}
 	for _, bd := range blockedDates {
-		dates = append(dates, bd.Date)
+		if !seen[bd.Date] {
+			seen[bd.Date] = true
+			dates = append(dates, bd.Date)
+		}
+	}
+
+	stayLimits, err := services.GetStayLimitRanges(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch stay rules"})
 	}
 
 	return c.JSON(fiber.Map{
 		"property_id":   id,
 		"blocked_dates": dates,
+		"stay_limits":   stayLimits,
 	})
 }
+
+// GetPropertyCalendar returns the days of a property from the from to the to query parameters, inclusive,
+// with the price of each night for the bedroom_config_id, its availability and its stay rules
+func GetPropertyCalendar(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	property, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil || property.Status != models.PropertyStatusPublished {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	if c.Query("from") == "" || c.Query("to") == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "from and to are required"})
+	}
+	from, err := time.Parse("2006-01-02", c.Query("from"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid from date format"})
+	}
+	to, err := time.Parse("2006-01-02", c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid to date format"})
+	}
+
+	calendar, err := services.BuildCalendar(property, from, to, c.Query("bedroom_config_id"))
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+	}
+	if errors.Is(err, services.ErrBedroomConfigNotFound) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid bedroom_config_id for this property"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to build calendar"})
+	}
+
+	return c.JSON(calendar)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetPropertyFees returns fees and taxes, optionally filtered by the property_id query parameter
+func GetPropertyFees(c *fiber.Ctx) error {
+	var fees []models.PropertyFee
+	var err error
+	if propertyID := c.Query("property_id"); propertyID != "" {
+		fees, err = repository.GetPropertyFeesByPropertyID(propertyID)
+	} else {
+		fees, err = repository.GetAllPropertyFees()
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch fees"})
+	}
+
+	return c.JSON(fees)
+}
+
+// CreatePropertyFee creates a fee or tax for a property
+func CreatePropertyFee(c *fiber.Ctx) error {
+	var req models.CreatePropertyFeeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.PropertyID == "" || req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name and property_id are required"})
+	}
+
+	property, err := repository.GetPropertyByID(req.PropertyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	err = services.ValidatePropertyFee(models.PropertyFee{
+		PropertyID:     req.PropertyID,
+		Type:           req.Type,
+		Amount:         req.Amount,
+		IncludedGuests: req.IncludedGuests,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	fee, err := repository.CreatePropertyFee(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create fee"})
+	}
+
+	return c.Status(201).JSON(fee)
+}
+
+// UpdatePropertyFee updates an existing fee or tax
+func UpdatePropertyFee(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdatePropertyFeeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	fee, err := repository.GetPropertyFeeByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch fee"})
+	}
+	if fee == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Fee not found"})
+	}
+
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	}
+	err = services.ValidatePropertyFee(models.PropertyFee{
+		PropertyID:     fee.PropertyID,
+		Type:           req.Type,
+		Amount:         req.Amount,
+		IncludedGuests: req.IncludedGuests,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	fee, err = repository.UpdatePropertyFee(id, req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update fee"})
+	}
+
+	return c.JSON(fee)
+}
+
+// DeletePropertyFee deletes a fee or tax
+func DeletePropertyFee(c *fiber.Ctx) error {
+	if err := repository.DeletePropertyFee(c.Params("id")); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete fee"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Fee deleted successfully"})
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetPropertyImages returns the images of a property in display order
+func GetPropertyImages(c *fiber.Ctx) error {
+	property, err := repository.GetPropertyByID(c.Params("id"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	properties := []models.Property{*property}
+	if err := services.AttachPropertyImages(properties); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property images"})
+	}
+
+	return c.JSON(properties[0].Images)
+}
+
+// AddPropertyImage adds a photo to a property, either uploaded as the multipart "file" field
+// or linked with a "url" field
+func AddPropertyImage(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	property, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	var req struct {
+		URL     string `json:"url" form:"url"`
+		Caption string `json:"caption" form:"caption"`
+		AltText string `json:"alt_text" form:"alt_text"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	var image *models.PropertyImage
+	if file, fileErr := c.FormFile("file"); fileErr == nil {
+		if file.Size > services.MaxImageUploadBytes {
+			return c.Status(413).JSON(fiber.Map{"error": "Image is too large (max 20 MB)"})
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Failed to read upload"})
+		}
+		data, err := io.ReadAll(io.LimitReader(f, services.MaxImageUploadBytes+1))
+		f.Close()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Failed to read upload"})
+		}
+		if len(data) > services.MaxImageUploadBytes {
+			return c.Status(413).JSON(fiber.Map{"error": "Image is too large (max 20 MB)"})
+		}
+
+		image, err = services.UploadPropertyImage(id, data, req.Caption, req.AltText)
+	} else if req.URL != "" {
+		image, err = services.AddExternalPropertyImage(id, req.URL, req.Caption, req.AltText)
+	} else {
+		return c.Status(400).JSON(fiber.Map{"error": "A file or url is required"})
+	}
+
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to add image"})
+	}
+
+	return c.Status(201).JSON(image)
+}
+
+// UpdatePropertyImage updates the caption and alt text of an image
+func UpdatePropertyImage(c *fiber.Ctx) error {
+	var req models.UpdatePropertyImageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	image, err := services.UpdatePropertyImage(c.Params("id"), c.Params("imageId"), req)
+	if errors.Is(err, services.ErrPropertyImageNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Image not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update image"})
+	}
+
+	return c.JSON(image)
+}
+
+// ReorderPropertyImages sets the display order of a property's images
+func ReorderPropertyImages(c *fiber.Ctx) error {
+	var req models.ReorderPropertyImagesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	err := services.ReorderPropertyImages(c.Params("id"), req.ImageIDs)
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to reorder images"})
+	}
+
+	return GetPropertyImages(c)
+}
+
+// DeletePropertyImage deletes an image and its stored files
+func DeletePropertyImage(c *fiber.Ctx) error {
+	err := services.DeletePropertyImage(c.Params("id"), c.Params("imageId"))
+	if errors.Is(err, services.ErrPropertyImageNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Image not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete image"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Image deleted successfully"})
+}
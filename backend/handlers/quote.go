@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateQuote prices a stay and saves the quote, which an enquiry can then use through its quote_id
+// to be charged the quoted price until the quote expires
+func CreateQuote(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.CreateQuoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.CheckIn == "" || req.CheckOut == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "check_in and check_out are required"})
+	}
+	checkIn, err := time.Parse("2006-01-02", req.CheckIn)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid check_in date format"})
+	}
+	checkOut, err := time.Parse("2006-01-02", req.CheckOut)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid check_out date format"})
+	}
+
+	property, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil || property.Status != models.PropertyStatusPublished {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	quote, err := services.CreateQuote(property, checkIn, checkOut, req.BedroomConfigID, req.Guests, req.PromoCode)
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+	}
+	if errors.Is(err, services.ErrBedroomConfigNotFound) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid bedroom_config_id for this property"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create quote"})
+	}
+
+	return c.Status(201).JSON(quote)
+}
@@ -1,15 +1,24 @@
 package handlers
 
 import (
+	"errors"
+	"time"
 	"villa-arama-riverside/models"
 	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// GetSeasons returns all seasons
+// GetSeasons returns all seasons, optionally filtered by the property_id query parameter
 func GetSeasons(c *fiber.Ctx) error {
-	seasons, err := repository.GetAllSeasons()
+	var seasons []models.Season
+	var err error
+	if propertyID := c.Query("property_id"); propertyID != "" {
+		seasons, err = repository.GetSeasonsByPropertyID(propertyID)
+	} else {
+		seasons, err = repository.GetAllSeasons()
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch seasons"})
 	}
@@ -24,8 +33,34 @@ func CreateSeason(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	if req.Name == "" || req.StartDate == "" || req.EndDate == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Name, start_date, and end_date are required"})
+	if req.PropertyID == "" || req.Name == "" || req.StartDate == "" || req.EndDate == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name, start_date, end_date, and property_id are required"})
+	}
+
+	property, err := repository.GetPropertyByID(req.PropertyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	err = services.ValidateSeason(models.Season{
+		PropertyID:         req.PropertyID,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		StartYear:          req.StartYear,
+		EndYear:            req.EndYear,
+		MinNights:          req.MinNights,
+		MaxNights:          req.MaxNights,
+		ClosedToArrival:    req.ClosedToArrival,
+		ClosedToDeparture:  req.ClosedToDeparture,
+		DailyPrice:         req.DailyPrice,
+		WeekdayAdjustments: req.WeekdayAdjustments,
+		IsDefault:          req.IsDefault,
+	})
+	if err != nil {
+		return seasonErrorResponse(c, err)
 	}
 
 	season, err := repository.CreateSeason(req)
@@ -45,15 +80,41 @@ func UpdateSeason(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
 
-	season, err := repository.UpdateSeason(id, req)
+	season, err := repository.GetSeasonByID(id)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update season"})
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch season"})
 	}
-
 	if season == nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Season not found"})
 	}
 
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	}
+	err = services.ValidateSeason(models.Season{
+		ID:                 id,
+		PropertyID:         season.PropertyID,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		StartYear:          req.StartYear,
+		EndYear:            req.EndYear,
+		MinNights:          req.MinNights,
+		MaxNights:          req.MaxNights,
+		ClosedToArrival:    req.ClosedToArrival,
+		ClosedToDeparture:  req.ClosedToDeparture,
+		DailyPrice:         req.DailyPrice,
+		WeekdayAdjustments: req.WeekdayAdjustments,
+		IsDefault:          req.IsDefault,
+	})
+	if err != nil {
+		return seasonErrorResponse(c, err)
+	}
+
+	season, err = repository.UpdateSeason(id, req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update season"})
+	}
+
 	return c.JSON(season)
 }
 
@@ -67,3 +128,45 @@ func DeleteSeason(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{"message": "Season deleted successfully"})
 }
+
+// GetSeasonOverlaps reports overlapping seasons of a property and which price wins on each day of a year
+func GetSeasonOverlaps(c *fiber.Ctx) error {
+	propertyID := c.Query("property_id")
+	if propertyID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "property_id is required"})
+	}
+
+	year := c.QueryInt("year", time.Now().Year())
+	if year < 2000 || year > 2100 {
+		return c.Status(400).JSON(fiber.Map{"error": "year must be between 2000 and 2100"})
+	}
+
+	property, err := repository.GetPropertyByID(propertyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	report, err := services.BuildSeasonOverlapReport(propertyID, year)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to build overlap report"})
+	}
+
+	return c.JSON(report)
+}
+
+// seasonErrorResponse maps errors from season validation to a response
+func seasonErrorResponse(c *fiber.Ctx, err error) error {
+	var validationErr *services.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+	case errors.Is(err, services.ErrDefaultSeasonExists):
+		return c.Status(409).JSON(fiber.Map{"error": "This property already has a default season"})
+	default:
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to validate season"})
+	}
+}
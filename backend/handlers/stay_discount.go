@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetStayDiscounts returns length-of-stay discounts, optionally filtered by the property_id query parameter
+func GetStayDiscounts(c *fiber.Ctx) error {
+	var discounts []models.StayDiscount
+	var err error
+	if propertyID := c.Query("property_id"); propertyID != "" {
+		discounts, err = repository.GetStayDiscountsByPropertyID(propertyID)
+	} else {
+		discounts, err = repository.GetAllStayDiscounts()
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch stay discounts"})
+	}
+
+	return c.JSON(discounts)
+}
+
+// CreateStayDiscount creates a length-of-stay discount
+func CreateStayDiscount(c *fiber.Ctx) error {
+	var req models.CreateStayDiscountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.PropertyID == "" || req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name and property_id are required"})
+	}
+
+	property, err := repository.GetPropertyByID(req.PropertyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	err = services.ValidateStayDiscount(models.StayDiscount{
+		PropertyID: req.PropertyID,
+		MinNights:  req.MinNights,
+		Type:       req.Type,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	discount, err := repository.CreateStayDiscount(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create stay discount"})
+	}
+
+	return c.Status(201).JSON(discount)
+}
+
+// UpdateStayDiscount updates an existing length-of-stay discount
+func UpdateStayDiscount(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdateStayDiscountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	discount, err := repository.GetStayDiscountByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch stay discount"})
+	}
+	if discount == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Stay discount not found"})
+	}
+
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	}
+	err = services.ValidateStayDiscount(models.StayDiscount{
+		ID:         id,
+		PropertyID: discount.PropertyID,
+		MinNights:  req.MinNights,
+		Type:       req.Type,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	discount, err = repository.UpdateStayDiscount(id, req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update stay discount"})
+	}
+
+	return c.JSON(discount)
+}
+
+// DeleteStayDiscount deletes a length-of-stay discount
+func DeleteStayDiscount(c *fiber.Ctx) error {
+	if err := repository.DeleteStayDiscount(c.Params("id")); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete stay discount"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Stay discount deleted successfully"})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetStayRules returns stay rules, optionally filtered by the property_id query parameter
+func GetStayRules(c *fiber.Ctx) error {
+	var rules []models.StayRule
+	var err error
+	if propertyID := c.Query("property_id"); propertyID != "" {
+		rules, err = repository.GetStayRulesByPropertyID(propertyID)
+	} else {
+		rules, err = repository.GetAllStayRules()
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch stay rules"})
+	}
+
+	return c.JSON(rules)
+}
+
+// CreateStayRule creates a minimum or maximum stay rule
+func CreateStayRule(c *fiber.Ctx) error {
+	var req models.CreateStayRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.PropertyID == "" || req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name and property_id are required"})
+	}
+	err := services.ValidateStayRule(models.StayRule{
+		StartDate:         req.StartDate,
+		EndDate:           req.EndDate,
+		MinNights:         req.MinNights,
+		MaxNights:         req.MaxNights,
+		ClosedToArrival:   req.ClosedToArrival,
+		ClosedToDeparture: req.ClosedToDeparture,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	property, err := repository.GetPropertyByID(req.PropertyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch property"})
+	}
+	if property == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Property not found"})
+	}
+
+	rule, err := repository.CreateStayRule(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create stay rule"})
+	}
+
+	return c.Status(201).JSON(rule)
+}
+
+// UpdateStayRule updates an existing stay rule
+func UpdateStayRule(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var req models.UpdateStayRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Name is required"})
+	}
+	err := services.ValidateStayRule(models.StayRule{
+		StartDate:         req.StartDate,
+		EndDate:           req.EndDate,
+		MinNights:         req.MinNights,
+		MaxNights:         req.MaxNights,
+		ClosedToArrival:   req.ClosedToArrival,
+		ClosedToDeparture: req.ClosedToDeparture,
+	})
+	if err != nil {
+		return validationErrorResponse(c, err)
+	}
+
+	rule, err := repository.UpdateStayRule(id, req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update stay rule"})
+	}
+	if rule == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Stay rule not found"})
+	}
+
+	return c.JSON(rule)
+}
+
+// DeleteStayRule deletes a stay rule
+func DeleteStayRule(c *fiber.Ctx) error {
+	if err := repository.DeleteStayRule(c.Params("id")); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete stay rule"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Stay rule deleted successfully"})
+}
+
+// validationErrorResponse maps errors from validating a pricing rule to a response
+func validationErrorResponse(c *fiber.Ctx, err error) error {
+	var validationErr *services.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(400).JSON(fiber.Map{"error": validationErr.Message})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "Failed to validate request"})
+}
@@ -0,0 +1,463 @@
+// Package ical parses iCalendar (RFC 5545) feeds such as those exported by Airbnb, Booking.com and VRBO.
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	// Embed the time zone database so TZID parameters resolve even on hosts without zoneinfo
+	_ "time/tzdata"
+)
+
+// ErrNotCalendar is returned when the input does not contain a VCALENDAR
+var ErrNotCalendar = errors.New("input is not an iCalendar feed")
+
+// ErrTruncated is returned when the input ends before the VCALENDAR is closed
+var ErrTruncated = errors.New("iCalendar feed is truncated")
+
+// Event is a VEVENT component
+type Event struct {
+	UID          string
+	Summary      string
+	Status       string // TENTATIVE, CONFIRMED or CANCELLED; empty if not given
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	RRule        string
+	ExDates      []time.Time
+	RecurrenceID time.Time // set on events that override one instance of a recurring event
+	Line         int       // line of the BEGIN:VEVENT, for error reporting
+}
+
+// Cancelled reports whether the event has STATUS:CANCELLED
+func (e Event) Cancelled() bool {
+	return strings.EqualFold(e.Status, "CANCELLED")
+}
+
+// ParseError describes a malformed event that was skipped
+type ParseError struct {
+	Line    int
+	UID     string
+	Message string
+}
+
+func (e ParseError) Error() string {
+	if e.UID != "" {
+		return fmt.Sprintf("line %d (UID %s): %s", e.Line, e.UID, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Calendar is a parsed VCALENDAR
+type Calendar struct {
+	Events []Event
+	Errors []ParseError
+}
+
+// Parse reads an iCalendar feed. Floating date-times (without Z or TZID) are interpreted in loc.
+// Malformed events are skipped and reported in Calendar.Errors; an error is only returned when the
+// feed as a whole is unusable, so callers can keep their existing data.
+func Parse(r io.Reader, loc *time.Location) (*Calendar, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &Calendar{}
+	var inCalendar, closed bool
+	var stack []string // open components inside the VCALENDAR
+	var current *rawEvent
+
+loop:
+	for _, l := range lines {
+		p, err := parseLine(l.text)
+		if err != nil {
+			if current != nil {
+				current.errs = append(current.errs, fmt.Sprintf("line %d: %v", l.num, err))
+			}
+			continue
+		}
+
+		switch p.name {
+		case "BEGIN":
+			component := strings.ToUpper(p.value)
+			if !inCalendar {
+				if component == "VCALENDAR" {
+					inCalendar = true
+				}
+				continue
+			}
+			stack = append(stack, component)
+			if component == "VEVENT" && len(stack) == 1 {
+				current = &rawEvent{line: l.num}
+			}
+			continue
+		case "END":
+			component := strings.ToUpper(p.value)
+			if !inCalendar {
+				continue
+			}
+			if len(stack) == 0 {
+				if component == "VCALENDAR" {
+					closed = true
+					break loop
+				}
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			if component == "VEVENT" && len(stack) == 0 && current != nil {
+				event, err := current.build(loc)
+				if err != nil {
+					cal.Errors = append(cal.Errors, ParseError{Line: current.line, UID: current.uid, Message: err.Error()})
+				} else {
+					cal.Events = append(cal.Events, event)
+				}
+				current = nil
+			}
+			continue
+		}
+
+		// Only properties directly inside a VEVENT matter; skip VALARM, VTIMEZONE and calendar properties
+		if current != nil && len(stack) == 1 {
+			current.add(p)
+		}
+	}
+
+	if !inCalendar {
+		return nil, ErrNotCalendar
+	}
+	if !closed {
+		return nil, ErrTruncated
+	}
+
+	return cal, nil
+}
+
+// line is an unfolded content line with the number of its first physical line
+type line struct {
+	num  int
+	text string
+}
+
+// unfold joins continuation lines (starting with a space or tab) onto the previous line
+func unfold(r io.Reader) ([]line, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []line
+	num := 0
+	for scanner.Scan() {
+		num++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		if (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		lines = append(lines, line{num: num, text: text})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// property is a parsed content line: NAME;PARAM=VALUE:value
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseLine splits a content line into name, parameters and value, honouring quoted parameter values
+func parseLine(text string) (property, error) {
+	p := property{params: map[string]string{}}
+
+	inQuotes := false
+	nameEnd := -1
+	valueStart := -1
+	for i, r := range text {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ';' && !inQuotes && nameEnd == -1:
+			nameEnd = i
+		case r == ':' && !inQuotes:
+			valueStart = i
+		}
+		if valueStart != -1 {
+			break
+		}
+	}
+
+	if valueStart == -1 {
+		return p, fmt.Errorf("missing ':' in %q", text)
+	}
+	if nameEnd == -1 || nameEnd > valueStart {
+		nameEnd = valueStart
+	}
+
+	p.name = strings.ToUpper(strings.TrimSpace(text[:nameEnd]))
+	p.value = text[valueStart+1:]
+
+	if nameEnd < valueStart {
+		for _, param := range splitUnquoted(text[nameEnd+1:valueStart], ';') {
+			key, value, ok := strings.Cut(param, "=")
+			if !ok {
+				continue
+			}
+			p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+
+	if p.name == "" {
+		return p, fmt.Errorf("missing property name in %q", text)
+	}
+	return p, nil
+}
+
+// splitUnquoted splits s on sep, ignoring separators inside double quotes
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescapeText reverses TEXT value escaping
+func unescapeText(s string) string {
+	r := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return r.Replace(s)
+}
+
+// rawEvent collects the properties of a VEVENT until it is complete
+type rawEvent struct {
+	line         int
+	uid          string
+	summary      string
+	status       string
+	dtStart      *property
+	dtEnd        *property
+	duration     string
+	rrule        string
+	exDates      []property
+	recurrenceID *property
+	errs         []string
+}
+
+func (e *rawEvent) add(p property) {
+	switch p.name {
+	case "UID":
+		e.uid = strings.TrimSpace(p.value)
+	case "SUMMARY":
+		e.summary = unescapeText(p.value)
+	case "STATUS":
+		e.status = strings.ToUpper(strings.TrimSpace(p.value))
+	case "DTSTART":
+		e.dtStart = &p
+	case "DTEND":
+		e.dtEnd = &p
+	case "DURATION":
+		e.duration = strings.TrimSpace(p.value)
+	case "RRULE":
+		e.rrule = strings.TrimSpace(p.value)
+	case "EXDATE":
+		e.exDates = append(e.exDates, p)
+	case "RECURRENCE-ID":
+		e.recurrenceID = &p
+	}
+}
+
+// build validates the collected properties and turns them into an Event
+func (e *rawEvent) build(loc *time.Location) (Event, error) {
+	if len(e.errs) > 0 {
+		return Event{}, errors.New(strings.Join(e.errs, "; "))
+	}
+	if e.dtStart == nil {
+		return Event{}, errors.New("missing DTSTART")
+	}
+
+	start, allDay, err := parseDateTime(*e.dtStart, loc)
+	if err != nil {
+		return Event{}, fmt.Errorf("invalid DTSTART: %w", err)
+	}
+
+	var end time.Time
+	switch {
+	case e.dtEnd != nil:
+		var endAllDay bool
+		end, endAllDay, err = parseDateTime(*e.dtEnd, loc)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid DTEND: %w", err)
+		}
+		if endAllDay != allDay {
+			return Event{}, errors.New("DTSTART and DTEND must both be dates or both be date-times")
+		}
+	case e.duration != "":
+		d, err := parseDuration(e.duration)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid DURATION: %w", err)
+		}
+		end = d.addTo(start)
+	case allDay:
+		// An all-day event without DTEND lasts one day
+		end = start.AddDate(0, 0, 1)
+	default:
+		end = start
+	}
+
+	if end.Before(start) {
+		return Event{}, errors.New("DTEND is before DTSTART")
+	}
+
+	event := Event{
+		UID:     e.uid,
+		Summary: e.summary,
+		Status:  e.status,
+		Start:   start,
+		End:     end,
+		AllDay:  allDay,
+		RRule:   e.rrule,
+		Line:    e.line,
+	}
+
+	for _, p := range e.exDates {
+		for _, value := range strings.Split(p.value, ",") {
+			exDate, _, err := parseDateTime(property{name: p.name, params: p.params, value: value}, loc)
+			if err != nil {
+				return Event{}, fmt.Errorf("invalid EXDATE: %w", err)
+			}
+			event.ExDates = append(event.ExDates, exDate)
+		}
+	}
+
+	if e.recurrenceID != nil {
+		event.RecurrenceID, _, err = parseDateTime(*e.recurrenceID, loc)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid RECURRENCE-ID: %w", err)
+		}
+	}
+
+	if event.RRule != "" {
+		if _, err := parseRRule(event.RRule, loc); err != nil {
+			return Event{}, fmt.Errorf("invalid RRULE: %w", err)
+		}
+	}
+
+	return event, nil
+}
+
+// parseDateTime parses a DATE or DATE-TIME value. Dates are returned at midnight UTC;
+// date-times use their TZID, UTC for a trailing Z, or loc when floating.
+func parseDateTime(p property, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(value) == 8 {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	if tzid := p.params["TZID"]; tzid != "" {
+		if tz, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = tz
+		}
+		// Unknown (e.g. Windows) time zone names fall back to the property's zone
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// duration is a parsed RFC 5545 DURATION
+type duration struct {
+	days int
+	time time.Duration
+	neg  bool
+}
+
+// addTo adds the duration to t, using calendar days so all-day events keep their wall time
+func (d duration) addTo(t time.Time) time.Time {
+	if d.neg {
+		return t.AddDate(0, 0, -d.days).Add(-d.time)
+	}
+	return t.AddDate(0, 0, d.days).Add(d.time)
+}
+
+// parseDuration parses values such as P1D, P2W, PT12H or P1DT2H30M
+func parseDuration(s string) (duration, error) {
+	var d duration
+	orig := s
+
+	if strings.HasPrefix(s, "-") {
+		d.neg = true
+		s = s[1:]
+	}
+	s = strings.TrimPrefix(s, "+")
+	if !strings.HasPrefix(s, "P") {
+		return d, fmt.Errorf("%q does not start with P", orig)
+	}
+	s = s[1:]
+
+	inTime := false
+	n := -1
+	for _, r := range s {
+		switch {
+		case r == 'T':
+			inTime = true
+		case r >= '0' && r <= '9':
+			if n < 0 {
+				n = 0
+			}
+			n = n*10 + int(r-'0')
+		default:
+			if n < 0 {
+				return d, fmt.Errorf("malformed duration %q", orig)
+			}
+			switch {
+			case r == 'W' && !inTime:
+				d.days += 7 * n
+			case r == 'D' && !inTime:
+				d.days += n
+			case r == 'H' && inTime:
+				d.time += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				d.time += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				d.time += time.Duration(n) * time.Second
+			default:
+				return d, fmt.Errorf("malformed duration %q", orig)
+			}
+			n = -1
+		}
+	}
+
+	if n >= 0 {
+		return d, fmt.Errorf("malformed duration %q", orig)
+	}
+	return d, nil
+}
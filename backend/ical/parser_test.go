@@ -0,0 +1,335 @@
+package ical
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var makassar = mustLoadLocation("Asia/Makassar")
+var london = mustLoadLocation("Europe/London")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func parseFixture(t *testing.T, name string, loc *time.Location) *Calendar {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cal, err := Parse(f, loc)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", name, err)
+	}
+	return cal
+}
+
+func eventByUID(t *testing.T, cal *Calendar, uid string) Event {
+	t.Helper()
+	for _, e := range cal.Events {
+		if e.UID == uid && e.RecurrenceID.IsZero() {
+			return e
+		}
+	}
+	t.Fatalf("no event with UID %s", uid)
+	return Event{}
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		file      string
+		uids      []string
+		errorUIDs []string
+	}{
+		{
+			file: "airbnb.ics",
+			uids: []string{
+				"1418fb94e984-6a4c7fd3b1a2c9e8f0d5b7a3c1e2f4d6@airbnb.com",
+				"7f5f1c3b2a1e-0a1b2c3d4e5f60718293a4b5c6d7e8f9@airbnb.com",
+			},
+		},
+		{
+			file: "booking.ics",
+			uids: []string{
+				"b2a9d3c1e4f5a6b7c8d9e0f1a2b3c4d5@booking.com",
+				"c3b0e4d2f5a6b7c8d9e0f1a2b3c4d5e6@booking.com",
+			},
+		},
+		{
+			file:      "recurring.ics",
+			uids:      []string{"daily-count", "weekly-byday", "weekly-override", "weekly-override", "floating", "cancelled", "after-errors"},
+			errorUIDs: []string{"no-start", "bad-end", "backwards", "bad-rule", "bad-line"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			cal := parseFixture(t, tt.file, makassar)
+
+			var uids []string
+			for _, e := range cal.Events {
+				uids = append(uids, e.UID)
+			}
+			if strings.Join(uids, ",") != strings.Join(tt.uids, ",") {
+				t.Errorf("events = %v, want %v", uids, tt.uids)
+			}
+
+			var errorUIDs []string
+			for _, e := range cal.Errors {
+				errorUIDs = append(errorUIDs, e.UID)
+				if e.Line == 0 || e.Message == "" {
+					t.Errorf("error %+v has no line or message", e)
+				}
+			}
+			if strings.Join(errorUIDs, ",") != strings.Join(tt.errorUIDs, ",") {
+				t.Errorf("errors = %v, want %v", cal.Errors, tt.errorUIDs)
+			}
+		})
+	}
+}
+
+func TestParseMalformedEvents(t *testing.T) {
+	cal := parseFixture(t, "recurring.ics", makassar)
+
+	tests := []struct {
+		uid     string
+		message string
+	}{
+		{"no-start", "missing DTSTART"},
+		{"bad-end", "invalid DTEND"},
+		{"backwards", "DTEND is before DTSTART"},
+		{"bad-rule", "invalid RRULE"},
+		{"bad-line", "missing ':'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.uid, func(t *testing.T) {
+			for _, e := range cal.Errors {
+				if e.UID == tt.uid {
+					if !strings.Contains(e.Message, tt.message) {
+						t.Errorf("message = %q, want it to contain %q", e.Message, tt.message)
+					}
+					return
+				}
+			}
+			t.Errorf("no error reported for %s", tt.uid)
+		})
+	}
+}
+
+func TestParseUnfoldsLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary string
+		want    string
+	}{
+		{"space", "SUMMARY:Reser\r\n ved", "Reserved"},
+		{"tab", "SUMMARY:Reser\r\n\tved", "Reserved"},
+		{"several", "SUMMARY:Not\r\n  avail\r\n able", "Not available"},
+		{"bare newline", "SUMMARY:Reser\n ved", "Reserved"},
+		{"escaped", `SUMMARY:Airbnb\, not available`, "Airbnb, not available"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:fold\r\nDTSTART;VALUE=DATE:20261101\r\n" +
+				tt.summary + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+			cal, err := Parse(strings.NewReader(input), time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cal.Events) != 1 {
+				t.Fatalf("got %d events, errors %v", len(cal.Events), cal.Errors)
+			}
+			if cal.Events[0].Summary != tt.want {
+				t.Errorf("summary = %q, want %q", cal.Events[0].Summary, tt.want)
+			}
+		})
+	}
+
+	airbnb := parseFixture(t, "airbnb.ics", time.UTC)
+	if airbnb.Events[0].Summary != "Reserved" {
+		t.Errorf("folded DESCRIPTION leaked into SUMMARY: %q", airbnb.Events[0].Summary)
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   time.Time
+		allDay bool
+	}{
+		{"date", "DTSTART;VALUE=DATE:20261101", date(2026, time.November, 1), true},
+		{"date without VALUE", "DTSTART:20261101", date(2026, time.November, 1), true},
+		{"utc", "DTSTART:20261101T140000Z", time.Date(2026, time.November, 1, 14, 0, 0, 0, time.UTC), false},
+		{"tzid", "DTSTART;TZID=Europe/London:20261101T140000", time.Date(2026, time.November, 1, 14, 0, 0, 0, london), false},
+		{"quoted tzid", `DTSTART;TZID="Europe/London":20260701T140000`, time.Date(2026, time.July, 1, 14, 0, 0, 0, london), false},
+		{"floating", "DTSTART:20261101T140000", time.Date(2026, time.November, 1, 14, 0, 0, 0, makassar), false},
+		{"unknown tzid", "DTSTART;TZID=W. Europe Standard Time:20261101T140000", time.Date(2026, time.November, 1, 14, 0, 0, 0, makassar), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseLine(tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, allDay, err := parseDateTime(p, makassar)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) || allDay != tt.allDay {
+				t.Errorf("got %v (all day %v), want %v (all day %v)", got, allDay, tt.want, tt.allDay)
+			}
+		})
+	}
+}
+
+func TestNightsExcludeDTEND(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		uid   string
+		first time.Time
+		count int
+	}{
+		{"airbnb reservation", "airbnb.ics", "1418fb94e984-6a4c7fd3b1a2c9e8f0d5b7a3c1e2f4d6@airbnb.com", date(2026, time.November, 1), 4},
+		{"booking closed", "booking.ics", "b2a9d3c1e4f5a6b7c8d9e0f1a2b3c4d5@booking.com", date(2026, time.November, 20), 3},
+		{"single night", "booking.ics", "c3b0e4d2f5a6b7c8d9e0f1a2b3c4d5e6@booking.com", date(2026, time.November, 24), 1},
+		{"floating date-time", "recurring.ics", "floating", date(2026, time.December, 20), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := eventByUID(t, parseFixture(t, tt.file, makassar), tt.uid)
+			nights := Occurrence{UID: e.UID, Start: e.Start, End: e.End, AllDay: e.AllDay}.Nights(makassar)
+
+			if len(nights) != tt.count {
+				t.Fatalf("got %d nights %v, want %d", len(nights), nights, tt.count)
+			}
+			if !nights[0].Equal(tt.first) {
+				t.Errorf("first night = %v, want %v", nights[0], tt.first)
+			}
+			checkout := tt.first.AddDate(0, 0, tt.count)
+			for _, n := range nights {
+				if !n.Before(checkout) {
+					t.Errorf("night %v is on or after checkout %v", n, checkout)
+				}
+			}
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	cal := parseFixture(t, "recurring.ics", makassar)
+	occurrences := cal.Occurrences(date(2026, time.October, 1), date(2027, time.January, 1), makassar)
+
+	byUID := map[string][]time.Time{}
+	for _, o := range occurrences {
+		byUID[o.UID] = append(byUID[o.UID], o.Start)
+	}
+
+	tests := []struct {
+		name string
+		uid  string
+		want []time.Time
+	}{
+		{"daily with COUNT", "daily-count", []time.Time{
+			date(2026, time.November, 1), date(2026, time.November, 2), date(2026, time.November, 3),
+		}},
+		{"weekly BYDAY with UNTIL and EXDATE", "weekly-byday", []time.Time{
+			date(2026, time.November, 2), date(2026, time.November, 9), date(2026, time.November, 13), date(2026, time.November, 16),
+		}},
+		{"RECURRENCE-ID override", "weekly-override", []time.Time{
+			time.Date(2026, time.December, 3, 15, 0, 0, 0, london),
+			time.Date(2026, time.December, 11, 9, 0, 0, 0, london),
+			time.Date(2026, time.December, 17, 15, 0, 0, 0, london),
+		}},
+		{"floating", "floating", []time.Time{time.Date(2026, time.December, 20, 6, 0, 0, 0, time.UTC)}},
+		{"cancelled", "cancelled", nil},
+		{"after malformed events", "after-errors", []time.Time{date(2026, time.December, 28)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := byUID[tt.uid]
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	for i := 1; i < len(occurrences); i++ {
+		if occurrences[i].Start.Before(occurrences[i-1].Start) {
+			t.Fatalf("occurrences are not sorted by start: %v before %v", occurrences[i-1].Start, occurrences[i].Start)
+		}
+	}
+}
+
+func TestOccurrencesWindow(t *testing.T) {
+	cal := parseFixture(t, "recurring.ics", makassar)
+
+	// Only the second daily instance overlaps the window
+	occurrences := cal.Occurrences(date(2026, time.November, 2), date(2026, time.November, 3), makassar)
+	var daily []Occurrence
+	for _, o := range occurrences {
+		if o.UID == "daily-count" {
+			daily = append(daily, o)
+		}
+	}
+	if len(daily) != 1 || !daily[0].Start.Equal(date(2026, time.November, 2)) || !daily[0].End.Equal(date(2026, time.November, 3)) {
+		t.Errorf("got %v, want the instance of November 2", daily)
+	}
+}
+
+func TestParseUnusableFeeds(t *testing.T) {
+	truncated, err := os.ReadFile(filepath.Join("testdata", "truncated.ics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"truncated fixture", string(truncated), ErrTruncated},
+		{"cut after event", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\nDTSTART;VALUE=DATE:20261101\r\nEND:VEVENT\r\n", ErrTruncated},
+		{"empty", "", ErrNotCalendar},
+		{"html error page", "<!DOCTYPE html>\n<html><body>Too Many Requests</body></html>\n", ErrNotCalendar},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal, err := Parse(strings.NewReader(tt.input), time.UTC)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+			if cal != nil {
+				t.Errorf("got a calendar for an unusable feed: %+v", cal)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxInstances bounds recurrence expansion so a malformed rule cannot loop forever
+const maxInstances = 10000
+
+// Occurrence is a single instance of an event
+type Occurrence struct {
+	UID    string
+	Start  time.Time
+	End    time.Time
+	AllDay bool
+}
+
+// Nights returns the nights an occurrence covers as dates at midnight UTC. DTEND is exclusive,
+// so a stay from the 1st to the 5th covers the nights of the 1st to the 4th and leaves the
+// checkout day free. Date-times are converted to loc before taking their date; an occurrence
+// that starts and ends on the same day still blocks that day.
+func (o Occurrence) Nights(loc *time.Location) []time.Time {
+	start, end := o.Start, o.End
+	if !o.AllDay {
+		start, end = start.In(loc), end.In(loc)
+	}
+
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	if !last.After(first) {
+		return []time.Time{first}
+	}
+
+	var nights []time.Time
+	for d := first; d.Before(last); d = d.AddDate(0, 0, 1) {
+		nights = append(nights, d)
+	}
+	return nights
+}
+
+// Occurrences expands the calendar into the instances that overlap [from, to). Cancelled events
+// and instances are left out, RRULEs are expanded, EXDATEs are skipped and instances overridden
+// by a RECURRENCE-ID event are replaced by that event.
+func (c *Calendar) Occurrences(from, to time.Time, loc *time.Location) []Occurrence {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	// Collect overridden instances per UID
+	overridden := map[string][]time.Time{}
+	for _, e := range c.Events {
+		if !e.RecurrenceID.IsZero() {
+			overridden[e.UID] = append(overridden[e.UID], e.RecurrenceID)
+		}
+	}
+
+	var occurrences []Occurrence
+	for _, e := range c.Events {
+		if e.Cancelled() {
+			continue
+		}
+
+		if e.RRule == "" || !e.RecurrenceID.IsZero() {
+			if overlaps(e.Start, e.End, from, to) {
+				occurrences = append(occurrences, Occurrence{UID: e.UID, Start: e.Start, End: e.End, AllDay: e.AllDay})
+			}
+			continue
+		}
+
+		rule, err := parseRRule(e.RRule, loc)
+		if err != nil {
+			// Parse already rejected events with invalid rules
+			continue
+		}
+
+		skip := append(append([]time.Time{}, e.ExDates...), overridden[e.UID]...)
+		length := e.End.Sub(e.Start)
+		days := daysBetween(e.Start, e.End)
+
+		for _, start := range rule.expand(e.Start, to) {
+			if containsTime(skip, start) {
+				continue
+			}
+
+			end := start.Add(length)
+			if e.AllDay {
+				end = start.AddDate(0, 0, days)
+			}
+			if !overlaps(start, end, from, to) {
+				continue
+			}
+
+			occurrences = append(occurrences, Occurrence{UID: e.UID, Start: start, End: end, AllDay: e.AllDay})
+		}
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+	return occurrences
+}
+
+// rrule is the supported subset of an RFC 5545 recurrence rule
+type rrule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRRule parses FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL and, for weekly
+// rules, BYDAY. Other parts are rejected rather than silently misread.
+func parseRRule(s string, loc *time.Location) (rrule, error) {
+	r := rrule{interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, fmt.Errorf("malformed part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(value)
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return r, fmt.Errorf("invalid COUNT %q", value)
+			}
+			r.count = n
+		case "UNTIL":
+			until, _, err := parseDateTime(property{value: value, params: map[string]string{}}, loc)
+			if err != nil {
+				return r, fmt.Errorf("invalid UNTIL %q", value)
+			}
+			r.until = until
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				wd, ok := weekdays[strings.ToUpper(day)]
+				if !ok {
+					return r, fmt.Errorf("unsupported BYDAY %q", day)
+				}
+				r.byDay = append(r.byDay, wd)
+			}
+		case "WKST":
+			// Only affects BYDAY expansion with INTERVAL > 1; weeks start on Monday here
+		default:
+			return r, fmt.Errorf("unsupported part %q", key)
+		}
+	}
+
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	case "":
+		return r, fmt.Errorf("missing FREQ")
+	default:
+		return r, fmt.Errorf("unsupported FREQ %q", r.freq)
+	}
+
+	if len(r.byDay) > 0 && r.freq != "WEEKLY" {
+		return r, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+
+	return r, nil
+}
+
+// expand returns the instance start times from dtStart up to (but excluding) to
+func (r rrule) expand(dtStart, to time.Time) []time.Time {
+	var starts []time.Time
+	generated := 0
+
+	emit := func(t time.Time) bool {
+		if t.Before(dtStart) {
+			return true
+		}
+		if !r.until.IsZero() && t.After(r.until) || !t.Before(to) {
+			return false
+		}
+		if r.count > 0 && generated >= r.count || generated >= maxInstances {
+			return false
+		}
+		generated++
+		starts = append(starts, t)
+		return true
+	}
+
+	for k := 0; k < maxInstances; k++ {
+		switch r.freq {
+		case "DAILY":
+			if !emit(dtStart.AddDate(0, 0, k*r.interval)) {
+				return starts
+			}
+		case "WEEKLY":
+			if len(r.byDay) == 0 {
+				if !emit(dtStart.AddDate(0, 0, 7*k*r.interval)) {
+					return starts
+				}
+				continue
+			}
+			// Monday of the k-th week, then each requested weekday in order
+			offset := (int(dtStart.Weekday()) + 6) % 7
+			monday := dtStart.AddDate(0, 0, 7*k*r.interval-offset)
+			days := make([]int, 0, len(r.byDay))
+			for _, wd := range r.byDay {
+				days = append(days, (int(wd)+6)%7)
+			}
+			sort.Ints(days)
+			for _, d := range days {
+				if !emit(monday.AddDate(0, 0, d)) {
+					return starts
+				}
+			}
+		case "MONTHLY":
+			t := dtStart.AddDate(0, k*r.interval, 0)
+			// Months without this day (e.g. the 31st) are skipped, not rolled over
+			if t.Day() != dtStart.Day() {
+				continue
+			}
+			if !emit(t) {
+				return starts
+			}
+		case "YEARLY":
+			t := dtStart.AddDate(k*r.interval, 0, 0)
+			if t.Day() != dtStart.Day() {
+				continue
+			}
+			if !emit(t) {
+				return starts
+			}
+		}
+	}
+
+	return starts
+}
+
+// overlaps reports whether [start, end) intersects [from, to); zero-length events count at their start
+func overlaps(start, end, from, to time.Time) bool {
+	if !start.Before(to) {
+		return false
+	}
+	if start.Equal(end) {
+		return !start.Before(from)
+	}
+	return end.After(from)
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+// containsTime reports whether list contains an instant equal to t
+func containsTime(list []time.Time, t time.Time) bool {
+	for _, l := range list {
+		if l.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,14 +3,25 @@ package main
 import (
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/joho/godotenv"
 
 	"villa-arama-riverside/database"
 	"villa-arama-riverside/handlers"
+	"villa-arama-riverside/middleware"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/services"
+	"villa-arama-riverside/storage"
 )
 
 func main() {
@@ -35,19 +46,45 @@ func main() {
 		log.Printf("Warning: Failed to seed data: %v", err)
 	}
 
-	// Create Fiber app
+	// Give every property a secret iCal export token
+	if err := services.EnsureICalExportTokens(); err != nil {
+		log.Printf("Warning: Failed to create iCal export tokens: %v", err)
+	}
+
+	// Create the first owner account if configured
+	if err := services.EnsureBootstrapAdmin(); err != nil {
+		log.Printf("Warning: Failed to create bootstrap admin: %v", err)
+	}
+
+	// Set up storage for uploaded photos
+	if err := storage.Init(); err != nil {
+		log.Fatalf("Failed to set up storage: %v", err)
+	}
+
+	// Create Fiber app. Bodies past the default limit are streamed rather than rejected so that
+	// photo uploads can be larger; LimitBody keeps the default limit on every other route.
 	app := fiber.New(fiber.Config{
-		AppName: "Villa Arama Riverside API",
+		AppName:                      "Villa Arama Riverside API",
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
 	})
 
 	// Middleware
 	app.Use(logger.New())
+	app.Use(recover.New())
+	app.Use(middleware.LimitBody(fiber.DefaultBodyLimit, isImageUpload))
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "http://localhost:3000",
-		AllowHeaders: "Origin, Content-Type, Accept",
-		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
+		AllowOrigins:     "http://localhost:3000",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
+		AllowCredentials: true,
 	}))
 
+	// Serve uploads from local disk
+	if local, ok := storage.Default.(*storage.Local); ok {
+		app.Static("/uploads", local.Dir, fiber.Static{MaxAge: 86400})
+	}
+
 	// API Routes
 	api := app.Group("/api")
 
@@ -56,17 +93,84 @@ func main() {
 	api.Get("/properties/:id", handlers.GetProperty)
 	api.Get("/properties/:id/pricing", handlers.GetPropertyPricing)
 	api.Get("/properties/:id/availability", handlers.GetPropertyAvailability)
+	api.Get("/properties/:id/calendar", handlers.GetPropertyCalendar)
+	api.Post("/properties/:id/quotes", limiter.New(limiter.Config{Max: 30, Expiration: time.Minute}), handlers.CreateQuote)
+	api.Get("/properties/:id/calendar.ics", handlers.ExportPropertyCalendar)
+	api.Get("/properties/:id/bedroom-configs", handlers.GetPropertyBedroomConfigs)
 	api.Post("/enquiries", handlers.CreateEnquiry)
 
-	// Admin routes
-	admin := api.Group("/admin")
+	// Auth routes
+	auth := api.Group("/auth")
+	auth.Post("/login", limiter.New(limiter.Config{Max: 10, Expiration: time.Minute}), handlers.Login)
+	auth.Post("/logout", handlers.Logout)
+	auth.Get("/me", middleware.RequireAuth, handlers.GetCurrentUser)
+
+	// Admin routes: every role can read, only owners and managers can write
+	admin := api.Group("/admin", middleware.RequireAuth, middleware.RequireWriteAccess)
+
+	// Admin users (owner only)
+	users := admin.Group("/users", middleware.RequireRole(models.RoleOwner))
+	users.Get("/", handlers.GetAdminUsers)
+	users.Post("/", handlers.CreateAdminUser)
+	users.Put("/:id", handlers.UpdateAdminUser)
+	users.Delete("/:id", handlers.DeleteAdminUser)
+
+	// Properties
+	admin.Get("/properties", handlers.GetAdminProperties)
+	admin.Get("/properties/:id", handlers.GetAdminProperty)
+	admin.Post("/properties", handlers.CreateProperty)
+	admin.Put("/properties/:id", handlers.UpdateProperty)
+	admin.Delete("/properties/:id", middleware.RequireRole(models.RoleOwner), handlers.DeleteProperty)
+	admin.Get("/properties/:id/images", handlers.GetPropertyImages)
+	admin.Post("/properties/:id/images", middleware.LimitBody(services.MaxImageUploadBytes+1<<20, nil), handlers.AddPropertyImage)
+	admin.Put("/properties/:id/images/order", handlers.ReorderPropertyImages)
+	admin.Put("/properties/:id/images/:imageId", handlers.UpdatePropertyImage)
+	admin.Delete("/properties/:id/images/:imageId", handlers.DeletePropertyImage)
 
 	// Seasons
 	admin.Get("/seasons", handlers.GetSeasons)
+	admin.Get("/seasons/overlaps", handlers.GetSeasonOverlaps)
 	admin.Post("/seasons", handlers.CreateSeason)
 	admin.Put("/seasons/:id", handlers.UpdateSeason)
 	admin.Delete("/seasons/:id", handlers.DeleteSeason)
 
+	// Date price overrides
+	admin.Get("/price-overrides", handlers.GetDatePriceOverrides)
+	admin.Post("/price-overrides", handlers.CreateDatePriceOverride)
+	admin.Put("/price-overrides/:id", handlers.UpdateDatePriceOverride)
+	admin.Delete("/price-overrides/:id", handlers.DeleteDatePriceOverride)
+
+	// Stay rules
+	admin.Get("/stay-rules", handlers.GetStayRules)
+	admin.Post("/stay-rules", handlers.CreateStayRule)
+	admin.Put("/stay-rules/:id", handlers.UpdateStayRule)
+	admin.Delete("/stay-rules/:id", handlers.DeleteStayRule)
+
+	// Length-of-stay discounts
+	admin.Get("/stay-discounts", handlers.GetStayDiscounts)
+	admin.Post("/stay-discounts", handlers.CreateStayDiscount)
+	admin.Put("/stay-discounts/:id", handlers.UpdateStayDiscount)
+	admin.Delete("/stay-discounts/:id", handlers.DeleteStayDiscount)
+
+	// Fees and taxes
+	admin.Get("/fees", handlers.GetPropertyFees)
+	admin.Post("/fees", handlers.CreatePropertyFee)
+	admin.Put("/fees/:id", handlers.UpdatePropertyFee)
+	admin.Delete("/fees/:id", handlers.DeletePropertyFee)
+
+	// Promo codes
+	admin.Get("/promo-codes", handlers.GetPromoCodes)
+	admin.Post("/promo-codes", handlers.CreatePromoCode)
+	admin.Put("/promo-codes/:id", handlers.UpdatePromoCode)
+	admin.Delete("/promo-codes/:id", handlers.DeletePromoCode)
+	admin.Get("/promo-codes/:id/redemptions", handlers.GetPromoRedemptions)
+
+	// Pricing rules
+	admin.Get("/pricing-rules", handlers.GetPricingRules)
+	admin.Post("/pricing-rules", handlers.CreatePricingRule)
+	admin.Put("/pricing-rules/:id", handlers.UpdatePricingRule)
+	admin.Delete("/pricing-rules/:id", handlers.DeletePricingRule)
+
 	// Bedroom Configs
 	admin.Get("/bedroom-configs", handlers.GetBedroomConfigs)
 	admin.Post("/bedroom-configs", handlers.CreateBedroomConfig)
@@ -81,8 +185,12 @@ func main() {
 	// iCal
 	admin.Get("/ical", handlers.GetICalURLs)
 	admin.Post("/ical", handlers.AddICalURL)
+	admin.Put("/ical/:id", handlers.UpdateICalURL)
+	admin.Get("/ical/:id/history", handlers.GetICalSyncHistory)
 	admin.Delete("/ical/:id", handlers.DeleteICalURL)
 	admin.Post("/ical/sync", handlers.SyncICalFeeds)
+	admin.Get("/properties/:id/ical-export", handlers.GetICalExportURL)
+	admin.Post("/properties/:id/ical-export/rotate", handlers.RotateICalExportToken)
 
 	// Get port from environment
 	port := os.Getenv("PORT")
@@ -90,6 +198,43 @@ func main() {
 		port = "3001"
 	}
 
+	// Start background iCal sync
+	scheduler := services.StartICalScheduler(services.ICalSchedulerConfig{
+		Workers:      envInt("ICAL_SYNC_WORKERS", 4),
+		PollInterval: time.Duration(envInt("ICAL_SYNC_POLL_SECONDS", 60)) * time.Second,
+		SyncTimeout:  2 * time.Minute,
+	})
+
+	// Shut down cleanly on SIGINT/SIGTERM
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+		<-quit
+
+		log.Println("Shutting down...")
+		if err := app.ShutdownWithTimeout(10 * time.Second); err != nil {
+			log.Printf("Server shutdown error: %v", err)
+		}
+	}()
+
 	log.Printf("Server starting on port %s", port)
-	log.Fatal(app.Listen(":" + port))
+	if err := app.Listen(":" + port); err != nil {
+		log.Printf("Server error: %v", err)
+	}
+
+	scheduler.Stop()
+}
+
+// envInt reads a positive integer from the environment, falling back to def
+func envInt(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// isImageUpload reports whether a request uploads a property photo, which may be larger than other bodies
+func isImageUpload(c *fiber.Ctx) bool {
+	path := strings.TrimSuffix(c.Path(), "/")
+	return c.Method() == fiber.MethodPost && strings.HasPrefix(path, "/api/admin/properties/") && strings.HasSuffix(path, "/images")
 }
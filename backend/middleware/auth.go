@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"strings"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SessionCookieName is the cookie holding the admin session token
+const SessionCookieName = "admin_session"
+
+// userLocalsKey is the fiber.Ctx locals key for the authenticated admin user
+const userLocalsKey = "admin_user"
+
+// SessionToken returns the session token from the Authorization header or the session cookie
+func SessionToken(c *fiber.Ctx) string {
+	if auth := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return c.Cookies(SessionCookieName)
+}
+
+// CurrentUser returns the admin user authenticated by RequireAuth
+func CurrentUser(c *fiber.Ctx) *models.AdminUser {
+	user, _ := c.Locals(userLocalsKey).(*models.AdminUser)
+	return user
+}
+
+// RequireAuth rejects requests without a valid admin session
+func RequireAuth(c *fiber.Ctx) error {
+	user, err := services.Authenticate(SessionToken(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify session"})
+	}
+
+	if user == nil {
+		return c.Status(401).JSON(fiber.Map{"error": "Authentication required"})
+	}
+
+	c.Locals(userLocalsKey, user)
+	return c.Next()
+}
+
+// RequireRole only lets through users with one of the given roles. It must run after RequireAuth.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := CurrentUser(c)
+		if user == nil {
+			return c.Status(401).JSON(fiber.Map{"error": "Authentication required"})
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				return c.Next()
+			}
+		}
+
+		return c.Status(403).JSON(fiber.Map{"error": "You do not have permission to perform this action"})
+	}
+}
+
+// RequireWriteAccess lets every role read, but only owners and managers change data. It must run after RequireAuth.
+func RequireWriteAccess(c *fiber.Ctx) error {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return c.Next()
+	}
+	return RequireRole(models.RoleOwner, models.RoleManager)(c)
+}
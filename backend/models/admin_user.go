@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Admin roles, from most to least privileged
+const (
+	RoleOwner   = "owner"   // full access, including admin user management
+	RoleManager = "manager" // can change pricing, calendars and enquiries
+	RoleStaff   = "staff"   // read-only access to the admin API
+)
+
+// AdminUser represents a user of the admin dashboard
+type AdminUser struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	Role         string    `json:"role"` // owner, manager, staff
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// AdminSession represents a logged-in admin session
+type AdminSession struct {
+	TokenHash string    `json:"-"`
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginRequest represents the request body for logging in
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// CreateAdminUserRequest represents the request body for creating an admin user
+type CreateAdminUserRequest struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Password string `json:"password"`
+}
+
+// UpdateAdminUserRequest represents the request body for updating an admin user.
+// An empty password leaves the current password unchanged.
+type UpdateAdminUserRequest struct {
+	Name     string `json:"name"`
+	Role     string `json:"role"`
+	Password string `json:"password"`
+}
+
+// IsValidRole reports whether role is one of the known admin roles
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleOwner, RoleManager, RoleStaff:
+		return true
+	}
+	return false
+}
@@ -5,9 +5,10 @@ import "time"
 // BedroomConfig represents a bedroom configuration option
 type BedroomConfig struct {
 	ID          string    `json:"id"`
+	PropertyID  string    `json:"property_id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
-	PriceAdd    float64   `json:"price_add"`
+	PriceAdd    Decimal   `json:"price_add"`
 	MaxGuests   int       `json:"max_guests"`
 	IsDefault   bool      `json:"is_default"`
 	CreatedAt   time.Time `json:"created_at"`
@@ -16,9 +17,10 @@ type BedroomConfig struct {
 
 // CreateBedroomConfigRequest represents the request body for creating a bedroom config
 type CreateBedroomConfigRequest struct {
+	PropertyID  string  `json:"property_id"`
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
-	PriceAdd    float64 `json:"price_add"`
+	PriceAdd    Decimal `json:"price_add"`
 	MaxGuests   int     `json:"max_guests"`
 	IsDefault   bool    `json:"is_default"`
 }
@@ -27,7 +29,7 @@ type CreateBedroomConfigRequest struct {
 type UpdateBedroomConfigRequest struct {
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
-	PriceAdd    float64 `json:"price_add"`
+	PriceAdd    Decimal `json:"price_add"`
 	MaxGuests   int     `json:"max_guests"`
 	IsDefault   bool    `json:"is_default"`
 }
@@ -2,31 +2,75 @@ package models
 
 import "time"
 
+// BlockedDateSourceDirect marks nights blocked by a confirmed direct enquiry
+const BlockedDateSourceDirect = "direct"
+
 // BlockedDate represents a date blocked for booking (from iCal sync)
 type BlockedDate struct {
 	ID         string    `json:"id"`
 	PropertyID string    `json:"property_id"`
 	Date       string    `json:"date"`
-	Source     string    `json:"source"` // airbnb, booking, manual
+	Source     string    `json:"source"` // airbnb, booking, manual, direct
 	EventUID   string    `json:"event_uid"`
+	ICalURLID  string    `json:"ical_url_id,omitempty"` // feed that imported this block, if any
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// Sync interval limits for iCal feeds, in minutes
+const (
+	DefaultICalSyncIntervalMinutes = 60
+	MinICalSyncIntervalMinutes     = 15
+)
+
 // ICalURL represents an iCal feed URL for a property
 type ICalURL struct {
-	ID         string    `json:"id"`
-	PropertyID string    `json:"property_id"`
-	URL        string    `json:"url"`
-	Source     string    `json:"source"` // airbnb, booking, other
-	LastSync   time.Time `json:"last_sync"`
-	Status     string    `json:"status"` // active, failed
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID                  string    `json:"id"`
+	PropertyID          string    `json:"property_id"`
+	URL                 string    `json:"url"`
+	Source              string    `json:"source"` // airbnb, booking, other
+	LastSync            time.Time `json:"last_sync"`
+	Status              string    `json:"status"` // active, failed
+	SyncIntervalMinutes int       `json:"sync_interval_minutes"`
+	NextSyncAt          time.Time `json:"next_sync_at"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error"` // reason of the last failed sync, empty once a sync succeeds
+	ETag                string    `json:"-"`          // validators of the last applied feed, sent on conditional requests
+	LastModified        string    `json:"-"`
+	ContentSyncedAt     time.Time `json:"content_synced_at"` // last time the feed's dates were fully applied
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 // CreateICalURLRequest represents the request for adding an iCal URL
 type CreateICalURLRequest struct {
-	PropertyID string `json:"property_id"`
-	URL        string `json:"url"`
-	Source     string `json:"source"`
+	PropertyID          string `json:"property_id"`
+	URL                 string `json:"url"`
+	Source              string `json:"source"`
+	SyncIntervalMinutes int    `json:"sync_interval_minutes"`
+}
+
+// Outcomes of an iCal sync run
+const (
+	ICalSyncSuccess = "success"
+	ICalSyncFailed  = "failed"
+)
+
+// ICalSyncRun records one sync of an iCal feed
+type ICalSyncRun struct {
+	ID            string    `json:"id"`
+	ICalURLID     string    `json:"ical_url_id"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+	Outcome       string    `json:"outcome"` // success, failed
+	HTTPStatus    int       `json:"http_status,omitempty"`
+	ErrorMessage  string    `json:"error_message"`
+	EventsParsed  int       `json:"events_parsed"`
+	EventsSkipped int       `json:"events_skipped"`
+	DatesAdded    int       `json:"dates_added"`
+	DatesRemoved  int       `json:"dates_removed"`
+}
+
+// UpdateICalURLRequest represents the request for changing an iCal URL's sync interval
+type UpdateICalURLRequest struct {
+	SyncIntervalMinutes int `json:"sync_interval_minutes"`
 }
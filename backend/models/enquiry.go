@@ -4,20 +4,22 @@ import "time"
 
 // Enquiry represents a booking enquiry from a customer
 type Enquiry struct {
-	ID              string    `json:"id"`
-	PropertyID      string    `json:"property_id"`
-	Name            string    `json:"name"`
-	Email           string    `json:"email"`
-	Phone           string    `json:"phone"`
-	CheckIn         string    `json:"check_in"`
-	CheckOut        string    `json:"check_out"`
-	Guests          int       `json:"guests"`
-	BedroomConfigID string    `json:"bedroom_config_id"`
-	Message         string    `json:"message"`
-	TotalPrice      float64   `json:"total_price"`
-	Status          string    `json:"status"` // pending, confirmed, cancelled
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              string      `json:"id"`
+	PropertyID      string      `json:"property_id"`
+	Name            string      `json:"name"`
+	Email           string      `json:"email"`
+	Phone           string      `json:"phone"`
+	CheckIn         string      `json:"check_in"`
+	CheckOut        string      `json:"check_out"`
+	Guests          int         `json:"guests"`
+	BedroomConfigID string      `json:"bedroom_config_id"`
+	Message         string      `json:"message"`
+	TotalPrice      Money       `json:"total_price"`
+	Currency        string      `json:"currency"`
+	Quote           *PriceQuote `json:"quote,omitempty"` // line items the total was priced with; nil for older enquiries
+	Status          string      `json:"status"`          // pending, confirmed, cancelled
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
 }
 
 // CreateEnquiryRequest represents the request body for creating an enquiry
@@ -31,6 +33,8 @@ type CreateEnquiryRequest struct {
 	Guests          int    `json:"guests"`
 	BedroomConfigID string `json:"bedroom_config_id"`
 	Message         string `json:"message"`
+	PromoCode       string `json:"promo_code"`
+	QuoteID         string `json:"quote_id"` // a saved quote for the same stay, whose price is honoured
 }
 
 // UpdateEnquiryStatusRequest represents the request for updating enquiry status
@@ -0,0 +1,293 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// DefaultCurrency is the currency of properties that have not set one
+const DefaultCurrency = "USD"
+
+// currencyExponents are the supported ISO 4217 currencies and their number of minor unit digits.
+// IDR keeps the 2 digits ISO 4217 gives it, as payment processors do, even though rupiah prices are
+// quoted in whole rupiah; such prices simply have no decimals.
+var currencyExponents = map[string]int{
+	"USD": 2,
+	"IDR": 2,
+	"EUR": 2,
+	"AUD": 2,
+	"SGD": 2,
+	"GBP": 2,
+	"JPY": 0,
+}
+
+// IsValidCurrency reports whether code is a supported ISO 4217 currency code
+func IsValidCurrency(code string) bool {
+	_, ok := currencyExponents[code]
+	return ok
+}
+
+// decimalPattern matches the decimal amounts accepted from JSON and the database
+var decimalPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// Decimal is an exact decimal number, such as a configured price in the property's currency or a
+// percentage. It is stored as NUMERIC and written to JSON as a string; JSON numbers are accepted too.
+type Decimal string
+
+// ParseDecimal parses a decimal number such as "350" or "12.50"
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if !decimalPattern.MatchString(s) {
+		return "", fmt.Errorf("invalid decimal %q", s)
+	}
+	return Decimal(s), nil
+}
+
+// Rat returns the value of d; the zero Decimal is 0
+func (d Decimal) Rat() *big.Rat {
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of d
+func (d Decimal) Sign() int {
+	return d.Rat().Sign()
+}
+
+// Cmp compares d with n, returning -1, 0 or 1
+func (d Decimal) Cmp(n int64) int {
+	return d.Rat().Cmp(big.NewRat(n, 1))
+}
+
+// Places returns the number of digits after the decimal point, ignoring trailing zeros
+func (d Decimal) Places() int {
+	_, frac, ok := strings.Cut(string(d), ".")
+	if !ok {
+		return 0
+	}
+	return len(strings.TrimRight(frac, "0"))
+}
+
+// String returns d as written, or "0" for the zero Decimal
+func (d Decimal) String() string {
+	if d == "" {
+		return "0"
+	}
+	return string(d)
+}
+
+// MarshalJSON writes d as a JSON string
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads a decimal from a JSON string or number
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*d = ""
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Scan reads a NUMERIC column
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		*d = Decimal(v)
+	case string:
+		*d = Decimal(v)
+	case int64:
+		*d = Decimal(fmt.Sprint(v))
+	case nil:
+		*d = ""
+	default:
+		return fmt.Errorf("cannot scan %T into Decimal", src)
+	}
+	return nil
+}
+
+// Value writes d to a NUMERIC column
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Money is an amount in the minor units of a currency, such as cents of USD. In JSON it is written
+// like a Decimal, in major units; the currency is given once by the object holding the amounts.
+type Money struct {
+	Minor    int64
+	Currency string
+
+	// amount holds an amount read from JSON until inCurrency gives it its currency
+	amount Decimal
+}
+
+// MoneyFromDecimal converts an amount in major units to money, rounding half away from zero
+// to the currency's minor unit
+func MoneyFromDecimal(d Decimal, currency string) Money {
+	exp, ok := currencyExponents[currency]
+	if !ok {
+		exp = 2
+	}
+	scaled := new(big.Rat).Mul(d.Rat(), new(big.Rat).SetInt(pow10(exp)))
+	return Money{Minor: roundRat(scaled), Currency: currency}
+}
+
+// Zero returns no money in a currency
+func Zero(currency string) Money {
+	return Money{Currency: currency}
+}
+
+// Add returns m + o; it panics when they are in different currencies
+func (m Money) Add(o Money) Money {
+	m.mustMatch(o)
+	return Money{Minor: m.Minor + o.Minor, Currency: m.Currency}
+}
+
+// Sub returns m - o; it panics when they are in different currencies
+func (m Money) Sub(o Money) Money {
+	m.mustMatch(o)
+	return Money{Minor: m.Minor - o.Minor, Currency: m.Currency}
+}
+
+// Mul returns m times a whole number
+func (m Money) Mul(n int) Money {
+	return Money{Minor: m.Minor * int64(n), Currency: m.Currency}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{Minor: -m.Minor, Currency: m.Currency}
+}
+
+// Percent returns rate percent of m, rounded half away from zero to the minor unit
+func (m Money) Percent(rate Decimal) Money {
+	r := new(big.Rat).Mul(big.NewRat(m.Minor, 100), rate.Rat())
+	return Money{Minor: roundRat(r), Currency: m.Currency}
+}
+
+// Min returns the smaller of m and o; it panics when they are in different currencies
+func (m Money) Min(o Money) Money {
+	m.mustMatch(o)
+	if o.Minor < m.Minor {
+		return o
+	}
+	return m
+}
+
+// mustMatch panics when m and o are in different currencies, which cannot be combined without
+// an exchange rate
+func (m Money) mustMatch(o Money) {
+	if m.Currency != o.Currency {
+		panic(fmt.Sprintf("money: cannot combine %s with %s", m.Currency, o.Currency))
+	}
+}
+
+// IsZero reports whether m is no money
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// Decimal returns m in major units with every minor unit digit, such as "350.00"
+func (m Money) Decimal() Decimal {
+	exp, ok := currencyExponents[m.Currency]
+	if !ok {
+		exp = 2
+	}
+
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	digits := fmt.Sprintf("%0*d", exp+1, minor)
+	if exp == 0 {
+		return Decimal(sign + digits)
+	}
+	return Decimal(sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:])
+}
+
+// String returns m with its currency code, such as "USD 350.00"
+func (m Money) String() string {
+	return m.Currency + " " + string(m.Decimal())
+}
+
+// MarshalJSON writes m in major units with every minor unit digit, such as "350.00"
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(m.Decimal()))
+}
+
+// UnmarshalJSON reads an amount written by MarshalJSON; inCurrency must then give it its currency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var amount Decimal
+	if err := json.Unmarshal(data, &amount); err != nil {
+		return err
+	}
+	*m = Money{amount: amount}
+	return nil
+}
+
+// inCurrency converts an amount read from JSON to money in currency
+func (m *Money) inCurrency(currency string) error {
+	if !CheckPrecision(m.amount, currency) {
+		return fmt.Errorf("amount %s has more decimals than %s allows", m.amount, currency)
+	}
+	*m = MoneyFromDecimal(m.amount, currency)
+	return nil
+}
+
+// moneyInCurrency converts amounts read from JSON to money in currency
+func moneyInCurrency(currency string, amounts ...*Money) error {
+	for _, m := range amounts {
+		if err := m.inCurrency(currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckPrecision reports whether a price in major units fits the minor unit of a currency
+func CheckPrecision(d Decimal, currency string) bool {
+	exp, ok := currencyExponents[currency]
+	return ok && d.Places() <= exp
+}
+
+// roundRat rounds r to a whole number, half away from zero
+func roundRat(r *big.Rat) int64 {
+	num, den := new(big.Int).Set(r.Num()), r.Denom()
+	neg := num.Sign() < 0
+	num.Abs(num)
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if neg {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
+
+// pow10 returns 10 to the power of n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,236 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMoneyFromDecimal(t *testing.T) {
+	tests := []struct {
+		amount   Decimal
+		currency string
+		minor    int64
+	}{
+		{"350", "USD", 35000},
+		{"350.5", "USD", 35050},
+		{"12.345", "USD", 1235}, // half rounds away from zero
+		{"12.344", "USD", 1234},
+		{"-12.345", "USD", -1235}, // and away from zero when negative
+		{"-0.004", "USD", 0},
+		{"", "USD", 0},
+		{"1500000", "IDR", 150000000},
+		{"1500", "JPY", 1500},
+		{"1500.5", "JPY", 1501},
+		{"-1500.5", "JPY", -1501},
+		{"1500.49", "JPY", 1500},
+	}
+
+	for _, tt := range tests {
+		m := MoneyFromDecimal(tt.amount, tt.currency)
+		if m.Minor != tt.minor || m.Currency != tt.currency {
+			t.Errorf("MoneyFromDecimal(%q, %s) = %d %s, want %d %s", tt.amount, tt.currency, m.Minor, m.Currency, tt.minor, tt.currency)
+		}
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  Decimal
+	}{
+		{Money{Minor: 35000, Currency: "USD"}, "350.00"},
+		{Money{Minor: 5, Currency: "USD"}, "0.05"},
+		{Money{Minor: 0, Currency: "USD"}, "0.00"},
+		{Money{Minor: -1235, Currency: "USD"}, "-12.35"},
+		{Money{Minor: -5, Currency: "EUR"}, "-0.05"},
+		{Money{Minor: 1500, Currency: "JPY"}, "1500"},
+		{Money{Minor: 0, Currency: "JPY"}, "0"},
+		{Money{Minor: -7, Currency: "JPY"}, "-7"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.want {
+			t.Errorf("%d %s: Decimal() = %q, want %q", tt.money.Minor, tt.money.Currency, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyPercent(t *testing.T) {
+	tests := []struct {
+		money Money
+		rate  Decimal
+		want  int64
+	}{
+		{Money{Minor: 10000, Currency: "USD"}, "15", 1500},
+		{Money{Minor: 1001, Currency: "USD"}, "15", 150},   // 150.15
+		{Money{Minor: 1010, Currency: "USD"}, "12.5", 126}, // 126.25
+		{Money{Minor: 1005, Currency: "JPY"}, "10", 101},   // 100.5 rounds away from zero
+		{Money{Minor: -1005, Currency: "JPY"}, "10", -101},
+		{Money{Minor: 1005, Currency: "JPY"}, "-10", -101},
+		{Money{Minor: 10000, Currency: "USD"}, "0", 0},
+	}
+
+	for _, tt := range tests {
+		got := tt.money.Percent(tt.rate)
+		if got.Minor != tt.want || got.Currency != tt.money.Currency {
+			t.Errorf("%s.Percent(%s) = %s, want %d minor units", tt.money, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := MoneyFromDecimal("10.50", "USD")
+	b := MoneyFromDecimal("3.25", "USD")
+
+	tests := []struct {
+		name string
+		got  Money
+		want Decimal
+	}{
+		{"Add", a.Add(b), "13.75"},
+		{"Sub", a.Sub(b), "7.25"},
+		{"Sub below zero", b.Sub(a), "-7.25"},
+		{"Mul", b.Mul(3), "9.75"},
+		{"Neg", a.Neg(), "-10.50"},
+		{"Min", a.Min(b), "3.25"},
+		{"Min of negative", a.Neg().Min(b), "-10.50"},
+	}
+
+	for _, tt := range tests {
+		if tt.got.Decimal() != tt.want || tt.got.Currency != "USD" {
+			t.Errorf("%s = %s, want USD %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestMoneyRefusesMixedCurrencies(t *testing.T) {
+	usd := MoneyFromDecimal("10", "USD")
+	jpy := MoneyFromDecimal("10", "JPY")
+
+	tests := []struct {
+		name string
+		op   func()
+	}{
+		{"Add", func() { usd.Add(jpy) }},
+		{"Sub", func() { usd.Sub(jpy) }},
+		{"Min", func() { usd.Min(jpy) }},
+	}
+
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s of USD and JPY did not panic", tt.name)
+				}
+			}()
+			tt.op()
+		}()
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		json     string
+		currency string
+		want     Decimal
+		err      string
+	}{
+		{`"350.00"`, "USD", "350.00", ""},
+		{`"350"`, "USD", "350.00", ""},
+		{`350`, "USD", "350.00", ""},
+		{`12.5`, "USD", "12.50", ""},
+		{`"-12.5"`, "USD", "-12.50", ""},
+		{`-12.5`, "USD", "-12.50", ""},
+		{`"1500"`, "JPY", "1500", ""},
+		{`1500`, "JPY", "1500", ""},
+		{`"12.345"`, "USD", "", "more decimals"},
+		{`12.345`, "USD", "", "more decimals"},
+		{`"1500.5"`, "JPY", "", "more decimals"},
+		{`"twelve"`, "USD", "", "invalid decimal"},
+		{`"1e3"`, "USD", "", "invalid decimal"},
+	}
+
+	for _, tt := range tests {
+		var m Money
+		err := json.Unmarshal([]byte(tt.json), &m)
+		if err == nil {
+			err = m.inCurrency(tt.currency)
+		}
+
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s in %s: got error %v, want %q", tt.json, tt.currency, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s in %s: %v", tt.json, tt.currency, err)
+			continue
+		}
+		if m.Decimal() != tt.want || m.Currency != tt.currency {
+			t.Errorf("%s in %s = %s, want %s %s", tt.json, tt.currency, m, tt.currency, tt.want)
+		}
+
+		out, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != `"`+string(tt.want)+`"` {
+			t.Errorf("%s marshals to %s, want %q", m, out, tt.want)
+		}
+	}
+}
+
+func TestPriceQuoteJSONRoundTrip(t *testing.T) {
+	usd := func(d Decimal) Money { return MoneyFromDecimal(d, "USD") }
+	fee := usd("25")
+	quote := PriceQuote{
+		Currency:      "USD",
+		Nights:        2,
+		Guests:        2,
+		Subtotal:      usd("700"),
+		Discount:      usd("0"),
+		PromoDiscount: usd("70"),
+		Fees:          usd("50"),
+		Taxes:         usd("68"),
+		TotalPrice:    usd("748"),
+		Lines: []QuoteLine{
+			{Type: QuoteLineAccommodation, Name: "2 nights", Quantity: 2, Amount: usd("700")},
+			{Type: QuoteLineFee, Name: "Cleaning", Quantity: 2, UnitPrice: &fee, Amount: usd("50")},
+			{Type: QuoteLineTax, Name: "VAT", Rate: "10", Amount: usd("68")},
+		},
+		Breakdown: []PropertyPricing{{
+			Date:             "2025-07-15",
+			Currency:         "USD",
+			SeasonDailyPrice: usd("350"),
+			BedroomPriceAdd:  usd("0"),
+			TotalPrice:       usd("350"),
+			RuleAdjustments:  []RuleAdjustment{{RuleID: "early", Percent: "-10", Amount: usd("-35")}},
+		}},
+	}
+
+	data, err := json.Marshal(quote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"total_price":"748.00"`) {
+		t.Errorf("total_price is not a decimal string: %s", data)
+	}
+
+	var got PriceQuote
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalPrice != quote.TotalPrice || *got.Lines[1].UnitPrice != fee ||
+		got.Breakdown[0].RuleAdjustments[0].Amount != usd("-35") || got.Breakdown[0].Currency != "USD" {
+		t.Errorf("round trip changed the quote:\n got %+v\nwant %+v", got, quote)
+	}
+
+	if err := json.Unmarshal([]byte(`{"currency":"XXX","total_price":"1"}`), &got); err == nil {
+		t.Error("quote in an unsupported currency was accepted")
+	}
+	if err := json.Unmarshal([]byte(`{"currency":"JPY","total_price":"1.5"}`), &got); err == nil {
+		t.Error("JPY quote with decimals was accepted")
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Pricing rule types
+const (
+	PricingRuleLeadTime  = "lead_time"
+	PricingRuleOccupancy = "occupancy"
+	PricingRuleGap       = "gap"
+)
+
+// DefaultOccupancyWindowDays is the number of nights occupancy rules look at when no window is set
+const DefaultOccupancyWindowDays = 30
+
+// PricingRule adjusts the price of nights after season pricing, by a percentage of the season's price for
+// the night. A lead_time rule applies to nights a number of days from today, an occupancy rule while the
+// nights around the night are booked to a certain share, and a gap rule to short runs of free nights
+// between two bookings. Every matching rule applies.
+type PricingRule struct {
+	ID         string `json:"id"`
+	PropertyID string `json:"property_id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"` // lead_time, occupancy, gap
+	// lead_time: days from today to the night, inclusive; nil for no limit
+	MinDaysOut *int `json:"min_days_out"`
+	MaxDaysOut *int `json:"max_days_out"`
+	// occupancy: percent of the window_days nights starting on the night that are blocked, inclusive
+	MinOccupancy *int `json:"min_occupancy"`
+	MaxOccupancy *int `json:"max_occupancy"`
+	WindowDays   int  `json:"window_days"`
+	// gap: longest run of free nights between two blocked nights the rule applies to
+	MaxGapNights int       `json:"max_gap_nights"`
+	Percent      Decimal   `json:"percent"` // negative for discounts
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreatePricingRuleRequest represents the request body for creating a pricing rule
+type CreatePricingRuleRequest struct {
+	PropertyID   string  `json:"property_id"`
+	Name         string  `json:"name"`
+	Type         string  `json:"type"`
+	MinDaysOut   *int    `json:"min_days_out"`
+	MaxDaysOut   *int    `json:"max_days_out"`
+	MinOccupancy *int    `json:"min_occupancy"`
+	MaxOccupancy *int    `json:"max_occupancy"`
+	WindowDays   int     `json:"window_days"`
+	MaxGapNights int     `json:"max_gap_nights"`
+	Percent      Decimal `json:"percent"`
+	Active       bool    `json:"active"`
+}
+
+// UpdatePricingRuleRequest represents the request body for updating a pricing rule
+type UpdatePricingRuleRequest struct {
+	Name         string  `json:"name"`
+	Type         string  `json:"type"`
+	MinDaysOut   *int    `json:"min_days_out"`
+	MaxDaysOut   *int    `json:"max_days_out"`
+	MinOccupancy *int    `json:"min_occupancy"`
+	MaxOccupancy *int    `json:"max_occupancy"`
+	WindowDays   int     `json:"window_days"`
+	MaxGapNights int     `json:"max_gap_nights"`
+	Percent      Decimal `json:"percent"`
+	Active       bool    `json:"active"`
+}
+
+// RuleAdjustment is the change a pricing rule made to the price of a night
+type RuleAdjustment struct {
+	RuleID  string  `json:"rule_id"`
+	Name    string  `json:"name"`
+	Percent Decimal `json:"percent"`
+	Amount  Money   `json:"amount"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Promo code discount types
+const (
+	PromoCodePercent = "percent"
+	PromoCodeAmount  = "amount"
+)
+
+// PromoCode is a code guests enter for a discount on a direct booking. Codes are matched case-insensitively
+// and stored in upper case. Empty dates and zero limits mean no restriction.
+type PromoCode struct {
+	ID         string  `json:"id"`
+	PropertyID string  `json:"property_id"`
+	Code       string  `json:"code"`
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`   // percent or amount
+	Amount     Decimal `json:"amount"` // percent, or price in the property's currency
+	// Dates the code can be used on, YYYY-MM-DD, inclusive
+	ValidFrom  *string `json:"valid_from"`
+	ValidUntil *string `json:"valid_until"`
+	// Dates every night of the stay must fall within, YYYY-MM-DD, inclusive
+	StayFrom        *string   `json:"stay_from"`
+	StayUntil       *string   `json:"stay_until"`
+	MinNights       int       `json:"min_nights"`
+	MaxUses         int       `json:"max_uses"`           // redemptions in total
+	MaxUsesPerEmail int       `json:"max_uses_per_email"` // redemptions per guest email
+	Active          bool      `json:"active"`
+	Redemptions     int       `json:"redemptions"` // by enquiries that are not cancelled
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CreatePromoCodeRequest represents the request body for creating a promo code
+type CreatePromoCodeRequest struct {
+	PropertyID      string  `json:"property_id"`
+	Code            string  `json:"code"`
+	Name            string  `json:"name"`
+	Type            string  `json:"type"`
+	Amount          Decimal `json:"amount"`
+	ValidFrom       *string `json:"valid_from"`
+	ValidUntil      *string `json:"valid_until"`
+	StayFrom        *string `json:"stay_from"`
+	StayUntil       *string `json:"stay_until"`
+	MinNights       int     `json:"min_nights"`
+	MaxUses         int     `json:"max_uses"`
+	MaxUsesPerEmail int     `json:"max_uses_per_email"`
+	Active          *bool   `json:"active"` // true when omitted
+}
+
+// UpdatePromoCodeRequest represents the request body for updating a promo code
+type UpdatePromoCodeRequest struct {
+	Code            string  `json:"code"`
+	Name            string  `json:"name"`
+	Type            string  `json:"type"`
+	Amount          Decimal `json:"amount"`
+	ValidFrom       *string `json:"valid_from"`
+	ValidUntil      *string `json:"valid_until"`
+	StayFrom        *string `json:"stay_from"`
+	StayUntil       *string `json:"stay_until"`
+	MinNights       int     `json:"min_nights"`
+	MaxUses         int     `json:"max_uses"`
+	MaxUsesPerEmail int     `json:"max_uses_per_email"`
+	Active          bool    `json:"active"`
+}
+
+// PromoRedemption records a promo code used by an enquiry
+type PromoRedemption struct {
+	ID            string    `json:"id"`
+	PromoCodeID   string    `json:"promo_code_id"`
+	EnquiryID     string    `json:"enquiry_id"`
+	Email         string    `json:"email"`
+	Amount        Money     `json:"amount"`
+	Currency      string    `json:"currency"`
+	EnquiryStatus string    `json:"enquiry_status"`
+	CreatedAt     time.Time `json:"created_at"`
+}
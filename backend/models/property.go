@@ -4,29 +4,112 @@ import "time"
 
 // Property represents a villa property
 type Property struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Tagline     string    `json:"tagline"`
-	Description string    `json:"description"`
-	Location    string    `json:"location"`
-	ImageURL    string    `json:"image_url"`
-	Images      []string  `json:"images"`
-	Amenities   []string  `json:"amenities"`
-	MaxGuests   int       `json:"max_guests"`
-	Bedrooms    int       `json:"bedrooms"`
-	Bathrooms   int       `json:"bathrooms"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Tagline     string          `json:"tagline"`
+	Description string          `json:"description"`
+	Location    string          `json:"location"`
+	ImageURL    string          `json:"image_url"`
+	Images      []PropertyImage `json:"images"`
+	Amenities   []string        `json:"amenities"`
+	MaxGuests   int             `json:"max_guests"`
+	Bedrooms    int             `json:"bedrooms"`
+	Bathrooms   int             `json:"bathrooms"`
+	Status      string          `json:"status"`   // draft, published, archived
+	Currency    string          `json:"currency"` // ISO 4217 code every price of the property is in
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Property statuses; only published properties are shown to guests
+const (
+	PropertyStatusDraft     = "draft"
+	PropertyStatusPublished = "published"
+	PropertyStatusArchived  = "archived"
+)
+
+// IsValidPropertyStatus reports whether status is one of the known property statuses
+func IsValidPropertyStatus(status string) bool {
+	switch status {
+	case PropertyStatusDraft, PropertyStatusPublished, PropertyStatusArchived:
+		return true
+	}
+	return false
+}
+
+// CreatePropertyRequest represents the request body for creating a property.
+// Status defaults to draft and currency to USD.
+type CreatePropertyRequest struct {
+	Name        string   `json:"name"`
+	Tagline     string   `json:"tagline"`
+	Description string   `json:"description"`
+	Location    string   `json:"location"`
+	ImageURL    string   `json:"image_url"`
+	Amenities   []string `json:"amenities"`
+	MaxGuests   int      `json:"max_guests"`
+	Bedrooms    int      `json:"bedrooms"`
+	Bathrooms   int      `json:"bathrooms"`
+	Status      string   `json:"status"`
+	Currency    string   `json:"currency"`
+}
+
+// UpdatePropertyRequest represents the request body for updating a property.
+// Omitted fields are left unchanged. Amenities can be replaced as a whole,
+// or edited with the add_ and remove_ lists; images are managed through their own endpoints.
+type UpdatePropertyRequest struct {
+	Name            *string   `json:"name"`
+	Tagline         *string   `json:"tagline"`
+	Description     *string   `json:"description"`
+	Location        *string   `json:"location"`
+	ImageURL        *string   `json:"image_url"`
+	Amenities       *[]string `json:"amenities"`
+	AddAmenities    []string  `json:"add_amenities"`
+	RemoveAmenities []string  `json:"remove_amenities"`
+	MaxGuests       *int      `json:"max_guests"`
+	Bedrooms        *int      `json:"bedrooms"`
+	Bathrooms       *int      `json:"bathrooms"`
+	Status          *string   `json:"status"`
+	Currency        *string   `json:"currency"`
 }
 
 // PropertyPricing represents dynamic pricing for a property
 type PropertyPricing struct {
-	PropertyID       string  `json:"property_id"`
-	Date             string  `json:"date"`
-	SeasonName       string  `json:"season_name"`
-	SeasonDailyPrice float64 `json:"season_daily_price"`
-	BedroomConfigID  string  `json:"bedroom_config_id,omitempty"`
-	BedroomName      string  `json:"bedroom_name,omitempty"`
-	BedroomPriceAdd  float64 `json:"bedroom_price_add"`
-	TotalPrice       float64 `json:"total_price"`
+	PropertyID       string `json:"property_id"`
+	Date             string `json:"date"`
+	Currency         string `json:"currency"`
+	SeasonName       string `json:"season_name"`
+	SeasonDailyPrice Money  `json:"season_daily_price"`
+	BedroomConfigID  string `json:"bedroom_config_id,omitempty"`
+	BedroomName      string `json:"bedroom_name,omitempty"`
+	BedroomPriceAdd  Money  `json:"bedroom_price_add"`
+	// Change to the season's daily price for the night's weekday, and its description
+	WeekdayAdjustment     Money  `json:"weekday_adjustment"`
+	WeekdayAdjustmentName string `json:"weekday_adjustment_name,omitempty"`
+	// Changes made by pricing rules, such as lead-time or gap-night discounts
+	RuleAdjustments []RuleAdjustment `json:"rule_adjustments,omitempty"`
+	TotalPrice      Money            `json:"total_price"`
+}
+
+// CalendarDay is one date of a property's calendar: the price of the night starting on it, whether
+// that night can be booked, and the stay rules of the date
+type CalendarDay struct {
+	Date       string `json:"date"` // YYYY-MM-DD
+	Price      Money  `json:"price"`
+	SeasonName string `json:"season_name"`
+	Available  bool   `json:"available"` // false for blocked and past nights
+	MinNights  int    `json:"min_nights"`
+	MaxNights  int    `json:"max_nights"` // 0 for no limit
+	// Whether guests cannot check in or check out on the date
+	ClosedToArrival   bool `json:"closed_to_arrival"`
+	ClosedToDeparture bool `json:"closed_to_departure"`
+}
+
+// PropertyCalendar lists the days of a property from one date to another, priced for a bedroom config
+type PropertyCalendar struct {
+	PropertyID      string        `json:"property_id"`
+	From            string        `json:"from"` // YYYY-MM-DD
+	To              string        `json:"to"`   // YYYY-MM-DD, inclusive
+	Currency        string        `json:"currency"`
+	BedroomConfigID string        `json:"bedroom_config_id,omitempty"`
+	Days            []CalendarDay `json:"days"`
 }
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Property fee types
+const (
+	FeePerStay          = "per_stay"            // charged once, such as a cleaning fee
+	FeePerNight         = "per_night"           // charged for every night
+	FeePerGuest         = "per_guest"           // charged once for every guest above IncludedGuests
+	FeePerGuestPerNight = "per_guest_per_night" // charged every night for every guest above IncludedGuests, such as tourist tax
+	FeePercent          = "percent"             // a tax of Amount percent on the accommodation and taxable fees, such as VAT
+)
+
+// PropertyFee is a fee or tax added to every quote of a property
+type PropertyFee struct {
+	ID             string    `json:"id"`
+	PropertyID     string    `json:"property_id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Amount         Decimal   `json:"amount"`          // price, or percent for percentage taxes
+	IncludedGuests int       `json:"included_guests"` // guests not charged by per-guest fees
+	Taxable        bool      `json:"taxable"`         // whether percentage taxes apply to this fee
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CreatePropertyFeeRequest represents the request body for creating a property fee
+type CreatePropertyFeeRequest struct {
+	PropertyID     string  `json:"property_id"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Amount         Decimal `json:"amount"`
+	IncludedGuests int     `json:"included_guests"`
+	Taxable        bool    `json:"taxable"`
+}
+
+// UpdatePropertyFeeRequest represents the request body for updating a property fee
+type UpdatePropertyFeeRequest struct {
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Amount         Decimal `json:"amount"`
+	IncludedGuests int     `json:"included_guests"`
+	Taxable        bool    `json:"taxable"`
+}
+
+// IsValidFeeType reports whether t is a known property fee type
+func IsValidFeeType(t string) bool {
+	switch t {
+	case FeePerStay, FeePerNight, FeePerGuest, FeePerGuestPerNight, FeePercent:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Image variants generated for every uploaded photo
+const (
+	ImageVariantThumbnail = "thumbnail"
+	ImageVariantCard      = "card"
+	ImageVariantHero      = "hero"
+)
+
+// PropertyImage represents a photo of a property, either uploaded or linked from an external URL
+type PropertyImage struct {
+	ID          string            `json:"id"`
+	PropertyID  string            `json:"property_id"`
+	URL         string            `json:"url"`      // original image
+	Variants    map[string]string `json:"variants"` // variant name to URL; empty for external images
+	StorageKey  string            `json:"-"`        // key prefix of uploaded files, empty for external images
+	ContentType string            `json:"content_type,omitempty"`
+	Width       int               `json:"width,omitempty"`
+	Height      int               `json:"height,omitempty"`
+	SizeBytes   int64             `json:"size_bytes,omitempty"`
+	Caption     string            `json:"caption"`
+	AltText     string            `json:"alt_text"`
+	SortOrder   int               `json:"sort_order"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// UpdatePropertyImageRequest represents the request body for updating an image's caption and alt text
+type UpdatePropertyImageRequest struct {
+	Caption *string `json:"caption"`
+	AltText *string `json:"alt_text"`
+}
+
+// ReorderPropertyImagesRequest lists every image of a property in the new order
+type ReorderPropertyImagesRequest struct {
+	ImageIDs []string `json:"image_ids"`
+}
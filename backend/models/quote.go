@@ -0,0 +1,107 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Quote line types
+const (
+	QuoteLineAccommodation = "accommodation"
+	QuoteLineDiscount      = "discount"
+	QuoteLineFee           = "fee"
+	QuoteLineTax           = "tax"
+)
+
+// QuoteLine is one line of a price quote
+type QuoteLine struct {
+	Type      string  `json:"type"` // accommodation, discount, fee, tax
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity,omitempty"`   // nights, guests or guest-nights charged
+	UnitPrice *Money  `json:"unit_price,omitempty"` // price per quantity, for fees
+	Rate      Decimal `json:"rate,omitempty"`       // percent, for taxes
+	Amount    Money   `json:"amount"`               // negative for discounts
+}
+
+// PriceQuote is the price of a stay in the property's currency: the nightly prices, the
+// length-of-stay and promo code discounts, the property's fees and taxes, and the total
+type PriceQuote struct {
+	Currency      string            `json:"currency"`
+	Nights        int               `json:"nights"`
+	Guests        int               `json:"guests"`
+	Subtotal      Money             `json:"subtotal"`                // sum of the nightly prices
+	Discount      Money             `json:"discount"`                // taken off the subtotal
+	DiscountName  string            `json:"discount_name,omitempty"` // the stay discount applied
+	PromoCode     string            `json:"promo_code,omitempty"`
+	PromoDiscount Money             `json:"promo_discount"` // taken off the subtotal after the stay discount
+	Fees          Money             `json:"fees"`
+	Taxes         Money             `json:"taxes"`
+	TotalPrice    Money             `json:"total_price"`
+	Lines         []QuoteLine       `json:"lines"`
+	Breakdown     []PropertyPricing `json:"breakdown"`
+}
+
+// UnmarshalJSON reads a quote written by json.Marshal, giving every amount the quote's currency
+func (q *PriceQuote) UnmarshalJSON(data []byte) error {
+	type plain PriceQuote
+	if err := json.Unmarshal(data, (*plain)(q)); err != nil {
+		return err
+	}
+	if !IsValidCurrency(q.Currency) {
+		return fmt.Errorf("unsupported currency %q", q.Currency)
+	}
+
+	err := moneyInCurrency(q.Currency, &q.Subtotal, &q.Discount, &q.PromoDiscount, &q.Fees, &q.Taxes, &q.TotalPrice)
+	if err != nil {
+		return err
+	}
+	for i := range q.Lines {
+		line := &q.Lines[i]
+		if line.UnitPrice != nil {
+			if err := line.UnitPrice.inCurrency(q.Currency); err != nil {
+				return err
+			}
+		}
+		if err := line.Amount.inCurrency(q.Currency); err != nil {
+			return err
+		}
+	}
+	for i := range q.Breakdown {
+		night := &q.Breakdown[i]
+		night.Currency = q.Currency
+		err := moneyInCurrency(q.Currency, &night.SeasonDailyPrice, &night.BedroomPriceAdd, &night.WeekdayAdjustment, &night.TotalPrice)
+		if err != nil {
+			return err
+		}
+		for j := range night.RuleAdjustments {
+			if err := night.RuleAdjustments[j].Amount.inCurrency(q.Currency); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Quote is a price quote saved for a guest. Until it expires, an enquiry for the same stay can use it
+// to be charged the quoted price, even if prices change in between. Each quote can be used once.
+type Quote struct {
+	ID              string    `json:"id"`
+	PropertyID      string    `json:"property_id"`
+	CheckIn         string    `json:"check_in"`                    // YYYY-MM-DD
+	CheckOut        string    `json:"check_out"`                   // YYYY-MM-DD
+	BedroomConfigID string    `json:"bedroom_config_id,omitempty"` // as requested, empty for the default config
+	ExpiresAt       time.Time `json:"expires_at"`
+	EnquiryID       *string   `json:"-"` // the enquiry that used the quote
+	CreatedAt       time.Time `json:"created_at"`
+	PriceQuote
+}
+
+// CreateQuoteRequest represents the request body for creating a quote
+type CreateQuoteRequest struct {
+	CheckIn         string `json:"check_in"`
+	CheckOut        string `json:"check_out"`
+	Guests          int    `json:"guests"`
+	BedroomConfigID string `json:"bedroom_config_id"`
+	PromoCode       string `json:"promo_code"`
+}
@@ -4,30 +4,133 @@ import "time"
 
 // Season represents a pricing season
 type Season struct {
+	ID         string `json:"id"`
+	PropertyID string `json:"property_id"`
+	Name       string `json:"name"`
+	StartDate  string `json:"start_date"` // MM-DD format
+	EndDate    string `json:"end_date"`   // MM-DD format
+	StartYear  *int   `json:"start_year"` // first year the season applies, nil for every year
+	EndYear    *int   `json:"end_year"`   // last year the season applies, nil for every year
+	Priority   int    `json:"priority"`   // wins over overlapping seasons with a lower priority
+	MinNights  int    `json:"min_nights"` // shortest stay over nights of this season, 0 for no limit
+	MaxNights  int    `json:"max_nights"` // longest stay over nights of this season, 0 for no limit
+	// Weekdays (0 = Sunday) on which guests cannot check in or check out during this season
+	ClosedToArrival   []int   `json:"closed_to_arrival"`
+	ClosedToDeparture []int   `json:"closed_to_departure"`
+	DailyPrice        Decimal `json:"daily_price"`
+	IsDefault         bool    `json:"is_default"`
+	// Price changes for nights starting on certain weekdays, such as Friday and Saturday
+	WeekdayAdjustments []WeekdayAdjustment `json:"weekday_adjustments"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+}
+
+// CreateSeasonRequest represents the request body for creating a season
+type CreateSeasonRequest struct {
+	PropertyID         string              `json:"property_id"`
+	Name               string              `json:"name"`
+	StartDate          string              `json:"start_date"`
+	EndDate            string              `json:"end_date"`
+	StartYear          *int                `json:"start_year"`
+	EndYear            *int                `json:"end_year"`
+	Priority           int                 `json:"priority"`
+	MinNights          int                 `json:"min_nights"`
+	MaxNights          int                 `json:"max_nights"`
+	ClosedToArrival    []int               `json:"closed_to_arrival"`
+	ClosedToDeparture  []int               `json:"closed_to_departure"`
+	DailyPrice         Decimal             `json:"daily_price"`
+	IsDefault          bool                `json:"is_default"`
+	WeekdayAdjustments []WeekdayAdjustment `json:"weekday_adjustments"`
+}
+
+// UpdateSeasonRequest represents the request body for updating a season
+type UpdateSeasonRequest struct {
+	Name               string              `json:"name"`
+	StartDate          string              `json:"start_date"`
+	EndDate            string              `json:"end_date"`
+	StartYear          *int                `json:"start_year"`
+	EndYear            *int                `json:"end_year"`
+	Priority           int                 `json:"priority"`
+	MinNights          int                 `json:"min_nights"`
+	MaxNights          int                 `json:"max_nights"`
+	ClosedToArrival    []int               `json:"closed_to_arrival"`
+	ClosedToDeparture  []int               `json:"closed_to_departure"`
+	DailyPrice         Decimal             `json:"daily_price"`
+	IsDefault          bool                `json:"is_default"`
+	WeekdayAdjustments []WeekdayAdjustment `json:"weekday_adjustments"`
+}
+
+// Weekday adjustment types
+const (
+	WeekdayAdjustmentPrice   = "price"   // the night costs Amount instead of the season's daily price
+	WeekdayAdjustmentPercent = "percent" // the season's daily price changes by Amount percent
+)
+
+// WeekdayAdjustment changes the price of a season's nights that start on a weekday
+type WeekdayAdjustment struct {
+	Weekday int     `json:"weekday"` // 0 = Sunday
+	Type    string  `json:"type"`    // price or percent
+	Amount  Decimal `json:"amount"`  // price in the property's currency, or percent
+}
+
+// SeasonRef identifies a season in reports
+type SeasonRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// SeasonOverlap is a run of days on which two seasons both apply
+type SeasonOverlap struct {
+	Seasons [2]SeasonRef `json:"seasons"`
+	From    string       `json:"from"` // YYYY-MM-DD
+	To      string       `json:"to"`   // YYYY-MM-DD, inclusive
+	Winner  SeasonRef    `json:"winner"`
+}
+
+// SeasonDay shows which price applies on a day and which seasons competed for it
+type SeasonDay struct {
+	Date       string      `json:"date"`
+	Source     string      `json:"source"` // override, season, default, none
+	Winner     *SeasonRef  `json:"winner"`
+	DailyPrice Decimal     `json:"daily_price"`
+	Candidates []SeasonRef `json:"candidates,omitempty"` // every matching season, when more than one applies
+}
+
+// SeasonOverlapReport lists the overlapping seasons of a property in a year
+type SeasonOverlapReport struct {
+	PropertyID string          `json:"property_id"`
+	Year       int             `json:"year"`
+	Overlaps   []SeasonOverlap `json:"overlaps"`
+	Days       []SeasonDay     `json:"days"`
+}
+
+// DatePriceOverride sets the nightly price of a property for a single date or a date range,
+// taking precedence over every season
+type DatePriceOverride struct {
 	ID         string    `json:"id"`
+	PropertyID string    `json:"property_id"`
 	Name       string    `json:"name"`
-	StartDate  string    `json:"start_date"` // MM-DD format
-	EndDate    string    `json:"end_date"`   // MM-DD format
-	DailyPrice float64   `json:"daily_price"`
-	IsDefault  bool      `json:"is_default"`
+	StartDate  string    `json:"start_date"` // YYYY-MM-DD
+	EndDate    string    `json:"end_date"`   // YYYY-MM-DD, inclusive
+	DailyPrice Decimal   `json:"daily_price"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
-// CreateSeasonRequest represents the request body for creating a season
-type CreateSeasonRequest struct {
+// CreateDatePriceOverrideRequest represents the request body for creating a date price override.
+// EndDate defaults to StartDate.
+type CreateDatePriceOverrideRequest struct {
+	PropertyID string  `json:"property_id"`
 	Name       string  `json:"name"`
 	StartDate  string  `json:"start_date"`
 	EndDate    string  `json:"end_date"`
-	DailyPrice float64 `json:"daily_price"`
-	IsDefault  bool    `json:"is_default"`
+	DailyPrice Decimal `json:"daily_price"`
 }
 
-// UpdateSeasonRequest represents the request body for updating a season
-type UpdateSeasonRequest struct {
+// UpdateDatePriceOverrideRequest represents the request body for updating a date price override
+type UpdateDatePriceOverrideRequest struct {
 	Name       string  `json:"name"`
 	StartDate  string  `json:"start_date"`
 	EndDate    string  `json:"end_date"`
-	DailyPrice float64 `json:"daily_price"`
-	IsDefault  bool    `json:"is_default"`
+	DailyPrice Decimal `json:"daily_price"`
 }
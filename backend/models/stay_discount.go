@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Stay discount types
+const (
+	StayDiscountPercent = "percent" // Amount percent off the stay
+	StayDiscountAmount  = "amount"  // a fixed Amount off the stay
+)
+
+// StayDiscount is a length-of-stay discount of a property, such as a weekly or monthly rate.
+// Only the discount with the highest MinNights a stay reaches applies.
+type StayDiscount struct {
+	ID         string    `json:"id"`
+	PropertyID string    `json:"property_id"`
+	Name       string    `json:"name"`
+	MinNights  int       `json:"min_nights"`
+	Type       string    `json:"type"` // percent or amount
+	Amount     Decimal   `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CreateStayDiscountRequest represents the request body for creating a stay discount
+type CreateStayDiscountRequest struct {
+	PropertyID string  `json:"property_id"`
+	Name       string  `json:"name"`
+	MinNights  int     `json:"min_nights"`
+	Type       string  `json:"type"`
+	Amount     Decimal `json:"amount"`
+}
+
+// UpdateStayDiscountRequest represents the request body for updating a stay discount
+type UpdateStayDiscountRequest struct {
+	Name      string  `json:"name"`
+	MinNights int     `json:"min_nights"`
+	Type      string  `json:"type"`
+	Amount    Decimal `json:"amount"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// StayRule limits the length of stays that include any night in its date range, and the weekdays
+// guests can check in or out on its dates. A rule without dates applies to every date of the property.
+type StayRule struct {
+	ID         string  `json:"id"`
+	PropertyID string  `json:"property_id"`
+	Name       string  `json:"name"`
+	StartDate  *string `json:"start_date"` // YYYY-MM-DD, nil for every date
+	EndDate    *string `json:"end_date"`   // YYYY-MM-DD, inclusive
+	MinNights  int     `json:"min_nights"` // 0 for no limit
+	MaxNights  int     `json:"max_nights"` // 0 for no limit
+	// Weekdays (0 = Sunday) on which guests cannot check in or check out
+	ClosedToArrival   []int     `json:"closed_to_arrival"`
+	ClosedToDeparture []int     `json:"closed_to_departure"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// CreateStayRuleRequest represents the request body for creating a stay rule
+type CreateStayRuleRequest struct {
+	PropertyID        string  `json:"property_id"`
+	Name              string  `json:"name"`
+	StartDate         *string `json:"start_date"`
+	EndDate           *string `json:"end_date"`
+	MinNights         int     `json:"min_nights"`
+	MaxNights         int     `json:"max_nights"`
+	ClosedToArrival   []int   `json:"closed_to_arrival"`
+	ClosedToDeparture []int   `json:"closed_to_departure"`
+}
+
+// UpdateStayRuleRequest represents the request body for updating a stay rule
+type UpdateStayRuleRequest struct {
+	Name              string  `json:"name"`
+	StartDate         *string `json:"start_date"`
+	EndDate           *string `json:"end_date"`
+	MinNights         int     `json:"min_nights"`
+	MaxNights         int     `json:"max_nights"`
+	ClosedToArrival   []int   `json:"closed_to_arrival"`
+	ClosedToDeparture []int   `json:"closed_to_departure"`
+}
+
+// StayLimitRange is a run of dates sharing the same stay rules, as shown to guests.
+// The stay length limits apply to nights, the arrival and departure flags to the dates themselves.
+type StayLimitRange struct {
+	From              string `json:"from"` // YYYY-MM-DD
+	To                string `json:"to"`   // YYYY-MM-DD, inclusive
+	MinNights         int    `json:"min_nights"`
+	MaxNights         int    `json:"max_nights"`
+	ClosedToArrival   bool   `json:"closed_to_arrival"`
+	ClosedToDeparture bool   `json:"closed_to_departure"`
+}
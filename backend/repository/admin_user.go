@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+)
+
+// GetAllAdminUsers returns all admin users
+func GetAllAdminUsers() ([]models.AdminUser, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, email, name, role, password_hash, created_at, updated_at
+		FROM admin_users
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.AdminUser
+	for rows.Next() {
+		var u models.AdminUser
+		err := rows.Scan(&u.ID, &u.Email, &u.Name, &u.Role, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, nil
+}
+
+// CountAdminUsers returns the number of admin users
+func CountAdminUsers() (int, error) {
+	var count int
+	err := database.DB.QueryRow("SELECT COUNT(*) FROM admin_users").Scan(&count)
+	return count, err
+}
+
+// CountAdminUsersByRole returns the number of admin users with the given role
+func CountAdminUsersByRole(role string) (int, error) {
+	var count int
+	err := database.DB.QueryRow("SELECT COUNT(*) FROM admin_users WHERE role = $1", role).Scan(&count)
+	return count, err
+}
+
+// GetAdminUserByID returns an admin user by ID
+func GetAdminUserByID(id string) (*models.AdminUser, error) {
+	var u models.AdminUser
+	err := database.DB.QueryRow(`
+		SELECT id, email, name, role, password_hash, created_at, updated_at
+		FROM admin_users
+		WHERE id = $1
+	`, id).Scan(&u.ID, &u.Email, &u.Name, &u.Role, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// GetAdminUserByEmail returns an admin user by email (case-insensitive)
+func GetAdminUserByEmail(email string) (*models.AdminUser, error) {
+	var u models.AdminUser
+	err := database.DB.QueryRow(`
+		SELECT id, email, name, role, password_hash, created_at, updated_at
+		FROM admin_users
+		WHERE LOWER(email) = LOWER($1)
+	`, email).Scan(&u.ID, &u.Email, &u.Name, &u.Role, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// CreateAdminUser creates a new admin user with an already hashed password
+func CreateAdminUser(email, name, role, passwordHash string) (*models.AdminUser, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO admin_users (id, email, name, role, password_hash, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, id, email, name, role, passwordHash, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetAdminUserByID(id)
+}
+
+// UpdateAdminUser updates an admin user's name and role, and the password hash if one is given
+func UpdateAdminUser(id, name, role, passwordHash string) (*models.AdminUser, error) {
+	_, err := database.DB.Exec(`
+		UPDATE admin_users
+		SET name = $1, role = $2, password_hash = COALESCE(NULLIF($3, ''), password_hash), updated_at = $4
+		WHERE id = $5
+	`, name, role, passwordHash, time.Now(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetAdminUserByID(id)
+}
+
+// DeleteAdminUser deletes an admin user and their sessions
+func DeleteAdminUser(id string) error {
+	_, err := database.DB.Exec("DELETE FROM admin_users WHERE id = $1", id)
+	return err
+}
+
+// CreateAdminSession stores a new session for a user, keyed by the hash of its token
+func CreateAdminSession(tokenHash, userID string, expiresAt time.Time) error {
+	_, err := database.DB.Exec(`
+		INSERT INTO admin_sessions (token_hash, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, tokenHash, userID, expiresAt, time.Now())
+	return err
+}
+
+// GetAdminUserBySession returns the user owning an unexpired session
+func GetAdminUserBySession(tokenHash string) (*models.AdminUser, error) {
+	var u models.AdminUser
+	err := database.DB.QueryRow(`
+		SELECT u.id, u.email, u.name, u.role, u.password_hash, u.created_at, u.updated_at
+		FROM admin_sessions s
+		JOIN admin_users u ON u.id = s.user_id
+		WHERE s.token_hash = $1 AND s.expires_at > $2
+	`, tokenHash, time.Now()).Scan(&u.ID, &u.Email, &u.Name, &u.Role, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// DeleteAdminSession deletes a session
+func DeleteAdminSession(tokenHash string) error {
+	_, err := database.DB.Exec("DELETE FROM admin_sessions WHERE token_hash = $1", tokenHash)
+	return err
+}
+
+// DeleteAdminSessionsByUserID deletes all sessions of a user
+func DeleteAdminSessionsByUserID(userID string) error {
+	_, err := database.DB.Exec("DELETE FROM admin_sessions WHERE user_id = $1", userID)
+	return err
+}
+
+// DeleteExpiredAdminSessions removes sessions that have expired
+func DeleteExpiredAdminSessions() error {
+	_, err := database.DB.Exec("DELETE FROM admin_sessions WHERE expires_at <= $1", time.Now())
+	return err
+}
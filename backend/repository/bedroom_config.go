@@ -12,7 +12,7 @@ import (
 // GetAllBedroomConfigs returns all bedroom configurations
 func GetAllBedroomConfigs() ([]models.BedroomConfig, error) {
 	rows, err := database.DB.Query(`
-		SELECT id, name, description, price_add, max_guests, is_default, created_at, updated_at
+		SELECT id, property_id, name, description, price_add, max_guests, is_default, created_at, updated_at
 		FROM bedroom_configs
 		ORDER BY price_add ASC
 	`)
@@ -24,7 +24,33 @@ func GetAllBedroomConfigs() ([]models.BedroomConfig, error) {
 	var configs []models.BedroomConfig
 	for rows.Next() {
 		var c models.BedroomConfig
-		err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.PriceAdd, &c.MaxGuests, &c.IsDefault, &c.CreatedAt, &c.UpdatedAt)
+		err := rows.Scan(&c.ID, &c.PropertyID, &c.Name, &c.Description, &c.PriceAdd, &c.MaxGuests, &c.IsDefault, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, c)
+	}
+
+	return configs, nil
+}
+
+// GetBedroomConfigsByPropertyID returns the bedroom configurations of a property
+func GetBedroomConfigsByPropertyID(propertyID string) ([]models.BedroomConfig, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, property_id, name, description, price_add, max_guests, is_default, created_at, updated_at
+		FROM bedroom_configs
+		WHERE property_id = $1
+		ORDER BY price_add ASC
+	`, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var configs []models.BedroomConfig
+	for rows.Next() {
+		var c models.BedroomConfig
+		err := rows.Scan(&c.ID, &c.PropertyID, &c.Name, &c.Description, &c.PriceAdd, &c.MaxGuests, &c.IsDefault, &c.CreatedAt, &c.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -38,10 +64,10 @@ func GetAllBedroomConfigs() ([]models.BedroomConfig, error) {
 func GetBedroomConfigByID(id string) (*models.BedroomConfig, error) {
 	var c models.BedroomConfig
 	err := database.DB.QueryRow(`
-		SELECT id, name, description, price_add, max_guests, is_default, created_at, updated_at
+		SELECT id, property_id, name, description, price_add, max_guests, is_default, created_at, updated_at
 		FROM bedroom_configs
 		WHERE id = $1
-	`, id).Scan(&c.ID, &c.Name, &c.Description, &c.PriceAdd, &c.MaxGuests, &c.IsDefault, &c.CreatedAt, &c.UpdatedAt)
+	`, id).Scan(&c.ID, &c.PropertyID, &c.Name, &c.Description, &c.PriceAdd, &c.MaxGuests, &c.IsDefault, &c.CreatedAt, &c.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -53,24 +79,25 @@ func GetBedroomConfigByID(id string) (*models.BedroomConfig, error) {
 	return &c, nil
 }
 
-// GetDefaultBedroomConfig returns the default bedroom config
-func GetDefaultBedroomConfig() (*models.BedroomConfig, error) {
+// GetDefaultBedroomConfig returns the default bedroom config of a property
+func GetDefaultBedroomConfig(propertyID string) (*models.BedroomConfig, error) {
 	var c models.BedroomConfig
 	err := database.DB.QueryRow(`
-		SELECT id, name, description, price_add, max_guests, is_default, created_at, updated_at
+		SELECT id, property_id, name, description, price_add, max_guests, is_default, created_at, updated_at
 		FROM bedroom_configs
-		WHERE is_default = true
+		WHERE property_id = $1 AND is_default = true
 		LIMIT 1
-	`).Scan(&c.ID, &c.Name, &c.Description, &c.PriceAdd, &c.MaxGuests, &c.IsDefault, &c.CreatedAt, &c.UpdatedAt)
+	`, propertyID).Scan(&c.ID, &c.PropertyID, &c.Name, &c.Description, &c.PriceAdd, &c.MaxGuests, &c.IsDefault, &c.CreatedAt, &c.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		// Return first config if no default
 		err = database.DB.QueryRow(`
-			SELECT id, name, description, price_add, max_guests, is_default, created_at, updated_at
+			SELECT id, property_id, name, description, price_add, max_guests, is_default, created_at, updated_at
 			FROM bedroom_configs
+			WHERE property_id = $1
 			ORDER BY price_add ASC
 			LIMIT 1
-		`).Scan(&c.ID, &c.Name, &c.Description, &c.PriceAdd, &c.MaxGuests, &c.IsDefault, &c.CreatedAt, &c.UpdatedAt)
+		`, propertyID).Scan(&c.ID, &c.PropertyID, &c.Name, &c.Description, &c.PriceAdd, &c.MaxGuests, &c.IsDefault, &c.CreatedAt, &c.UpdatedAt)
 	}
 
 	if err == sql.ErrNoRows {
@@ -89,9 +116,9 @@ func CreateBedroomConfig(req models.CreateBedroomConfigRequest) (*models.Bedroom
 	now := time.Now()
 
 	_, err := database.DB.Exec(`
-		INSERT INTO bedroom_configs (id, name, description, price_add, max_guests, is_default, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, id, req.Name, req.Description, req.PriceAdd, req.MaxGuests, req.IsDefault, now, now)
+		INSERT INTO bedroom_configs (id, property_id, name, description, price_add, max_guests, is_default, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, id, req.PropertyID, req.Name, req.Description, req.PriceAdd, req.MaxGuests, req.IsDefault, now, now)
 
 	if err != nil {
 		return nil, err
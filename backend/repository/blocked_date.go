@@ -6,13 +6,13 @@ import (
 	"villa-arama-riverside/database"
 	"villa-arama-riverside/models"
 
-	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-// GetBlockedDates returns all blocked dates for a property
+// GetBlockedDates returns all blocked dates for a property. A date blocked by several sources appears once per source.
 func GetBlockedDates(propertyID string) ([]models.BlockedDate, error) {
 	rows, err := database.DB.Query(`
-		SELECT id, property_id, date, source, event_uid, created_at
+		SELECT id, property_id, date, source, event_uid, ical_url_id, created_at
 		FROM blocked_dates
 		WHERE property_id = $1
 		ORDER BY date ASC
@@ -25,8 +25,8 @@ func GetBlockedDates(propertyID string) ([]models.BlockedDate, error) {
 	var dates []models.BlockedDate
 	for rows.Next() {
 		var d models.BlockedDate
-		var source, eventUID sql.NullString
-		err := rows.Scan(&d.ID, &d.PropertyID, &d.Date, &source, &eventUID, &d.CreatedAt)
+		var source, eventUID, icalURLID sql.NullString
+		err := rows.Scan(&d.ID, &d.PropertyID, &d.Date, &source, &eventUID, &icalURLID, &d.CreatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -36,155 +36,151 @@ func GetBlockedDates(propertyID string) ([]models.BlockedDate, error) {
 		if eventUID.Valid {
 			d.EventUID = eventUID.String
 		}
+		if icalURLID.Valid {
+			d.ICalURLID = icalURLID.String
+		}
 		dates = append(dates, d)
 	}
 
 	return dates, nil
 }
 
-// AddBlockedDate adds a blocked date
-func AddBlockedDate(propertyID, date, source, eventUID string) error {
-	id := uuid.New().String()
-	_, err := database.DB.Exec(`
-		INSERT INTO blocked_dates (id, property_id, date, source, event_uid, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (property_id, date) DO NOTHING
-	`, id, propertyID, date, source, eventUID, time.Now())
-	return err
-}
-
-// ClearBlockedDatesBySource clears blocked dates from a specific source
-func ClearBlockedDatesBySource(propertyID, source string) error {
-	_, err := database.DB.Exec(`
-		DELETE FROM blocked_dates
-		WHERE property_id = $1 AND source = $2
-	`, propertyID, source)
-	return err
-}
-
-// GetAllICalURLs returns all iCal URLs
-func GetAllICalURLs() ([]models.ICalURL, error) {
-	rows, err := database.DB.Query(`
-		SELECT id, property_id, url, source, last_sync, status, created_at, updated_at
-		FROM ical_urls
-		ORDER BY created_at DESC
-	`)
+// GetUnavailableNights returns the nights in [checkIn, checkOut) that are blocked or taken by a confirmed enquiry.
+// Dates are YYYY-MM-DD strings.
+func GetUnavailableNights(q database.Querier, propertyID, checkIn, checkOut string) ([]string, error) {
+	rows, err := q.Query(`
+		SELECT to_char(date, 'YYYY-MM-DD') AS night
+		FROM blocked_dates
+		WHERE property_id = $1 AND date >= $2::date AND date < $3::date
+		UNION
+		SELECT to_char(night, 'YYYY-MM-DD')
+		FROM enquiries e,
+			generate_series(GREATEST(e.check_in, $2::date)::timestamp, (LEAST(e.check_out, $3::date) - 1)::timestamp, interval '1 day') AS night
+		WHERE e.property_id = $1 AND e.status = 'confirmed'
+		AND e.check_in < $3::date AND e.check_out > $2::date
+		ORDER BY night ASC
+	`, propertyID, checkIn, checkOut)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var urls []models.ICalURL
+	var nights []string
 	for rows.Next() {
-		var u models.ICalURL
-		var source sql.NullString
-		var lastSync sql.NullTime
-		err := rows.Scan(&u.ID, &u.PropertyID, &u.URL, &source, &lastSync, &u.Status, &u.CreatedAt, &u.UpdatedAt)
-		if err != nil {
+		var night string
+		if err := rows.Scan(&night); err != nil {
 			return nil, err
 		}
-		if source.Valid {
-			u.Source = source.String
-		}
-		if lastSync.Valid {
-			u.LastSync = lastSync.Time
-		}
-		urls = append(urls, u)
+		nights = append(nights, night)
 	}
 
-	return urls, nil
+	return nights, rows.Err()
 }
 
-// GetICalURLsByPropertyID returns iCal URLs for a property
-func GetICalURLsByPropertyID(propertyID string) ([]models.ICalURL, error) {
+// GetBlockedNights returns the set of a property's blocked nights in [from, to]. Dates are YYYY-MM-DD strings.
+func GetBlockedNights(propertyID, from, to string) (map[string]bool, error) {
 	rows, err := database.DB.Query(`
-		SELECT id, property_id, url, source, last_sync, status, created_at, updated_at
-		FROM ical_urls
-		WHERE property_id = $1
-		ORDER BY created_at DESC
-	`, propertyID)
+		SELECT DISTINCT to_char(date, 'YYYY-MM-DD')
+		FROM blocked_dates
+		WHERE property_id = $1 AND date BETWEEN $2::date AND $3::date
+	`, propertyID, from, to)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var urls []models.ICalURL
+	nights := map[string]bool{}
 	for rows.Next() {
-		var u models.ICalURL
-		var source sql.NullString
-		var lastSync sql.NullTime
-		err := rows.Scan(&u.ID, &u.PropertyID, &u.URL, &source, &lastSync, &u.Status, &u.CreatedAt, &u.UpdatedAt)
-		if err != nil {
+		var night string
+		if err := rows.Scan(&night); err != nil {
 			return nil, err
 		}
-		if source.Valid {
-			u.Source = source.String
-		}
-		if lastSync.Valid {
-			u.LastSync = lastSync.Time
-		}
-		urls = append(urls, u)
+		nights[night] = true
 	}
 
-	return urls, nil
+	return nights, rows.Err()
 }
 
-// CreateICalURL creates a new iCal URL
-func CreateICalURL(req models.CreateICalURLRequest) (*models.ICalURL, error) {
-	id := uuid.New().String()
-	now := time.Now()
-
-	_, err := database.DB.Exec(`
-		INSERT INTO ical_urls (id, property_id, url, source, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, 'active', $5, $6)
-	`, id, req.PropertyID, req.URL, req.Source, now, now)
-
+// GetFeedBlockedDates returns the dates imported by an iCal feed, mapped to the UID of the event that blocked them
+func GetFeedBlockedDates(q database.Querier, icalURLID string) (map[string]string, error) {
+	rows, err := q.Query(`
+		SELECT to_char(date, 'YYYY-MM-DD'), COALESCE(event_uid, '')
+		FROM blocked_dates
+		WHERE ical_url_id = $1
+	`, icalURLID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	return GetICalURLByID(id)
+	dates := map[string]string{}
+	for rows.Next() {
+		var date, eventUID string
+		if err := rows.Scan(&date, &eventUID); err != nil {
+			return nil, err
+		}
+		dates[date] = eventUID
+	}
+
+	return dates, rows.Err()
 }
 
-// GetICalURLByID returns an iCal URL by ID
-func GetICalURLByID(id string) (*models.ICalURL, error) {
-	var u models.ICalURL
-	var source sql.NullString
-	var lastSync sql.NullTime
-	err := database.DB.QueryRow(`
-		SELECT id, property_id, url, source, last_sync, status, created_at, updated_at
-		FROM ical_urls
-		WHERE id = $1
-	`, id).Scan(&u.ID, &u.PropertyID, &u.URL, &source, &lastSync, &u.Status, &u.CreatedAt, &u.UpdatedAt)
-
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
+// AddFeedBlockedDates blocks dates for an iCal feed; dates and eventUIDs are parallel slices
+func AddFeedBlockedDates(q database.Querier, propertyID, icalURLID, source string, dates, eventUIDs []string) error {
+	if len(dates) == 0 {
+		return nil
 	}
-	if source.Valid {
-		u.Source = source.String
-	}
-	if lastSync.Valid {
-		u.LastSync = lastSync.Time
+	_, err := q.Exec(`
+		INSERT INTO blocked_dates (property_id, date, source, event_uid, ical_url_id, created_at)
+		SELECT $1, t.date, $2, t.event_uid, $3, $4
+		FROM unnest($5::date[], $6::text[]) AS t(date, event_uid)
+	`, propertyID, source, icalURLID, time.Now(), pq.Array(dates), pq.Array(eventUIDs))
+	return err
+}
+
+// UpdateFeedBlockedDateUIDs changes the event UID of dates an iCal feed already blocks
+func UpdateFeedBlockedDateUIDs(q database.Querier, icalURLID string, dates, eventUIDs []string) error {
+	if len(dates) == 0 {
+		return nil
 	}
+	_, err := q.Exec(`
+		UPDATE blocked_dates b
+		SET event_uid = t.event_uid
+		FROM unnest($2::date[], $3::text[]) AS t(date, event_uid)
+		WHERE b.ical_url_id = $1 AND b.date = t.date
+	`, icalURLID, pq.Array(dates), pq.Array(eventUIDs))
+	return err
+}
 
-	return &u, nil
+// RemoveFeedBlockedDates unblocks dates previously imported by an iCal feed
+func RemoveFeedBlockedDates(q database.Querier, icalURLID string, dates []string) error {
+	if len(dates) == 0 {
+		return nil
+	}
+	_, err := q.Exec(`
+		DELETE FROM blocked_dates
+		WHERE ical_url_id = $1 AND date = ANY($2::date[])
+	`, icalURLID, pq.Array(dates))
+	return err
 }
 
-// UpdateICalURLStatus updates the status and last sync time of an iCal URL
-func UpdateICalURLStatus(id string, status string) error {
-	_, err := database.DB.Exec(`
-		UPDATE ical_urls
-		SET status = $1, last_sync = $2, updated_at = $3
-		WHERE id = $4
-	`, status, time.Now(), time.Now(), id)
+// BlockEnquiryNights blocks every night of an enquiry's stay, tagged as a direct booking with the enquiry ID as event UID
+func BlockEnquiryNights(q database.Querier, enquiryID string) error {
+	_, err := q.Exec(`
+		INSERT INTO blocked_dates (property_id, date, source, event_uid, created_at)
+		SELECT e.property_id, night::date, $2, e.id::text, $3
+		FROM enquiries e,
+			generate_series(e.check_in::timestamp, (e.check_out - 1)::timestamp, interval '1 day') AS night
+		WHERE e.id = $1
+	`, enquiryID, models.BlockedDateSourceDirect, time.Now())
 	return err
 }
 
-// DeleteICalURL deletes an iCal URL
-func DeleteICalURL(id string) error {
-	_, err := database.DB.Exec("DELETE FROM ical_urls WHERE id = $1", id)
+// ReleaseEnquiryNights removes the blocked dates created for an enquiry
+func ReleaseEnquiryNights(q database.Querier, enquiryID string) error {
+	_, err := q.Exec(`
+		DELETE FROM blocked_dates
+		WHERE source = $1 AND event_uid = $2
+	`, models.BlockedDateSourceDirect, enquiryID)
 	return err
 }
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+)
+
+// dateOverrideColumns is the column list read by scanDatePriceOverride
+const dateOverrideColumns = `id, property_id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), daily_price, created_at, updated_at`
+
+// scanDatePriceOverride scans a row selected with dateOverrideColumns
+func scanDatePriceOverride(row rowScanner) (models.DatePriceOverride, error) {
+	var o models.DatePriceOverride
+	err := row.Scan(&o.ID, &o.PropertyID, &o.Name, &o.StartDate, &o.EndDate, &o.DailyPrice, &o.CreatedAt, &o.UpdatedAt)
+	return o, err
+}
+
+// queryDatePriceOverrides runs a query selecting dateOverrideColumns and scans every row
+func queryDatePriceOverrides(query string, args ...interface{}) ([]models.DatePriceOverride, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []models.DatePriceOverride
+	for rows.Next() {
+		o, err := scanDatePriceOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+
+	return overrides, nil
+}
+
+// GetAllDatePriceOverrides returns the date price overrides of every property, ordered by date
+func GetAllDatePriceOverrides() ([]models.DatePriceOverride, error) {
+	return queryDatePriceOverrides(`
+		SELECT ` + dateOverrideColumns + `
+		FROM date_price_overrides
+		ORDER BY start_date ASC
+	`)
+}
+
+// GetDatePriceOverridesByPropertyID returns the date price overrides of a property, ordered by date
+func GetDatePriceOverridesByPropertyID(propertyID string) ([]models.DatePriceOverride, error) {
+	return queryDatePriceOverrides(`
+		SELECT `+dateOverrideColumns+`
+		FROM date_price_overrides
+		WHERE property_id = $1
+		ORDER BY start_date ASC
+	`, propertyID)
+}
+
+// GetDatePriceOverrideByID returns a date price override by ID
+func GetDatePriceOverrideByID(id string) (*models.DatePriceOverride, error) {
+	o, err := scanDatePriceOverride(database.DB.QueryRow(`
+		SELECT `+dateOverrideColumns+`
+		FROM date_price_overrides
+		WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// CreateDatePriceOverride creates a new date price override
+func CreateDatePriceOverride(req models.CreateDatePriceOverrideRequest) (*models.DatePriceOverride, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO date_price_overrides (id, property_id, name, start_date, end_date, daily_price, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, id, req.PropertyID, req.Name, req.StartDate, req.EndDate, req.DailyPrice, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+	invalidateSeasonCache()
+
+	return GetDatePriceOverrideByID(id)
+}
+
+// UpdateDatePriceOverride updates an existing date price override
+func UpdateDatePriceOverride(id string, req models.UpdateDatePriceOverrideRequest) (*models.DatePriceOverride, error) {
+	_, err := database.DB.Exec(`
+		UPDATE date_price_overrides
+		SET name = $1, start_date = $2, end_date = $3, daily_price = $4, updated_at = $5
+		WHERE id = $6
+	`, req.Name, req.StartDate, req.EndDate, req.DailyPrice, time.Now(), id)
+
+	if err != nil {
+		return nil, err
+	}
+	invalidateSeasonCache()
+
+	return GetDatePriceOverrideByID(id)
+}
+
+// DeleteDatePriceOverride deletes a date price override
+func DeleteDatePriceOverride(id string) error {
+	_, err := database.DB.Exec("DELETE FROM date_price_overrides WHERE id = $1", id)
+	invalidateSeasonCache()
+	return err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 	"villa-arama-riverside/database"
 	"villa-arama-riverside/models"
@@ -12,7 +13,7 @@ import (
 // GetAllEnquiries returns all enquiries
 func GetAllEnquiries() ([]models.Enquiry, error) {
 	rows, err := database.DB.Query(`
-		SELECT id, property_id, name, email, phone, check_in, check_out, guests, bedroom_config_id, message, total_price, status, created_at, updated_at
+		SELECT id, property_id, name, email, phone, check_in, check_out, guests, bedroom_config_id, message, total_price, currency, quote, status, created_at, updated_at
 		FROM enquiries
 		ORDER BY created_at DESC
 	`)
@@ -25,13 +26,21 @@ func GetAllEnquiries() ([]models.Enquiry, error) {
 	for rows.Next() {
 		var e models.Enquiry
 		var bedroomConfigID sql.NullString
-		err := rows.Scan(&e.ID, &e.PropertyID, &e.Name, &e.Email, &e.Phone, &e.CheckIn, &e.CheckOut, &e.Guests, &bedroomConfigID, &e.Message, &e.TotalPrice, &e.Status, &e.CreatedAt, &e.UpdatedAt)
+		var totalPrice models.Decimal
+		var currency string
+		var quote []byte
+		err := rows.Scan(&e.ID, &e.PropertyID, &e.Name, &e.Email, &e.Phone, &e.CheckIn, &e.CheckOut, &e.Guests, &bedroomConfigID, &e.Message, &totalPrice, &currency, &quote, &e.Status, &e.CreatedAt, &e.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
 		if bedroomConfigID.Valid {
 			e.BedroomConfigID = bedroomConfigID.String
 		}
+		e.TotalPrice = models.MoneyFromDecimal(totalPrice, currency)
+		e.Currency = currency
+		if e.Quote, err = decodeQuote(quote); err != nil {
+			return nil, err
+		}
 		enquiries = append(enquiries, e)
 	}
 
@@ -42,11 +51,14 @@ func GetAllEnquiries() ([]models.Enquiry, error) {
 func GetEnquiryByID(id string) (*models.Enquiry, error) {
 	var e models.Enquiry
 	var bedroomConfigID sql.NullString
+	var totalPrice models.Decimal
+	var currency string
+	var quote []byte
 	err := database.DB.QueryRow(`
-		SELECT id, property_id, name, email, phone, check_in, check_out, guests, bedroom_config_id, message, total_price, status, created_at, updated_at
+		SELECT id, property_id, name, email, phone, check_in, check_out, guests, bedroom_config_id, message, total_price, currency, quote, status, created_at, updated_at
 		FROM enquiries
 		WHERE id = $1
-	`, id).Scan(&e.ID, &e.PropertyID, &e.Name, &e.Email, &e.Phone, &e.CheckIn, &e.CheckOut, &e.Guests, &bedroomConfigID, &e.Message, &e.TotalPrice, &e.Status, &e.CreatedAt, &e.UpdatedAt)
+	`, id).Scan(&e.ID, &e.PropertyID, &e.Name, &e.Email, &e.Phone, &e.CheckIn, &e.CheckOut, &e.Guests, &bedroomConfigID, &e.Message, &totalPrice, &currency, &quote, &e.Status, &e.CreatedAt, &e.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -57,15 +69,25 @@ func GetEnquiryByID(id string) (*models.Enquiry, error) {
 	if bedroomConfigID.Valid {
 		e.BedroomConfigID = bedroomConfigID.String
 	}
+	e.TotalPrice = models.MoneyFromDecimal(totalPrice, currency)
+	e.Currency = currency
+	if e.Quote, err = decodeQuote(quote); err != nil {
+		return nil, err
+	}
 
 	return &e, nil
 }
 
-// CreateEnquiry creates a new enquiry
-func CreateEnquiry(req models.CreateEnquiryRequest, totalPrice float64) (*models.Enquiry, error) {
+// CreateEnquiryTx inserts a new pending enquiry priced with quote using q, which may be a transaction, and returns its ID
+func CreateEnquiryTx(q database.Querier, req models.CreateEnquiryRequest, quote *models.PriceQuote) (string, error) {
 	id := uuid.New().String()
 	now := time.Now()
 
+	quoteJSON, err := json.Marshal(quote)
+	if err != nil {
+		return "", err
+	}
+
 	var bedroomConfigID interface{}
 	if req.BedroomConfigID != "" {
 		bedroomConfigID = req.BedroomConfigID
@@ -73,29 +95,71 @@ func CreateEnquiry(req models.CreateEnquiryRequest, totalPrice float64) (*models
 		bedroomConfigID = nil
 	}
 
-	_, err := database.DB.Exec(`
-		INSERT INTO enquiries (id, property_id, name, email, phone, check_in, check_out, guests, bedroom_config_id, message, total_price, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'pending', $12, $13)
-	`, id, req.PropertyID, req.Name, req.Email, req.Phone, req.CheckIn, req.CheckOut, req.Guests, bedroomConfigID, req.Message, totalPrice, now, now)
+	_, err = q.Exec(`
+		INSERT INTO enquiries (id, property_id, name, email, phone, check_in, check_out, guests, bedroom_config_id, message, total_price, currency, quote, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, 'pending', $14, $15)
+	`, id, req.PropertyID, req.Name, req.Email, req.Phone, req.CheckIn, req.CheckOut, req.Guests, bedroomConfigID, req.Message,
+		quote.TotalPrice.Decimal(), quote.Currency, quoteJSON, now, now)
+
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// GetEnquiryForUpdate returns an enquiry by ID and locks it for the rest of the transaction.
+// Check-in and check-out are returned in YYYY-MM-DD format.
+func GetEnquiryForUpdate(tx *sql.Tx, id string) (*models.Enquiry, error) {
+	var e models.Enquiry
+	var bedroomConfigID sql.NullString
+	var totalPrice models.Decimal
+	var currency string
+	var quote []byte
+	err := tx.QueryRow(`
+		SELECT id, property_id, name, email, phone, to_char(check_in, 'YYYY-MM-DD'), to_char(check_out, 'YYYY-MM-DD'), guests, bedroom_config_id, message, total_price, currency, quote, status, created_at, updated_at
+		FROM enquiries
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&e.ID, &e.PropertyID, &e.Name, &e.Email, &e.Phone, &e.CheckIn, &e.CheckOut, &e.Guests, &bedroomConfigID, &e.Message, &totalPrice, &currency, &quote, &e.Status, &e.CreatedAt, &e.UpdatedAt)
 
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
+	if bedroomConfigID.Valid {
+		e.BedroomConfigID = bedroomConfigID.String
+	}
+	e.TotalPrice = models.MoneyFromDecimal(totalPrice, currency)
+	e.Currency = currency
+	if e.Quote, err = decodeQuote(quote); err != nil {
+		return nil, err
+	}
 
-	return GetEnquiryByID(id)
+	return &e, nil
 }
 
-// UpdateEnquiryStatus updates an enquiry's status
-func UpdateEnquiryStatus(id string, status string) (*models.Enquiry, error) {
-	_, err := database.DB.Exec(`
+// UpdateEnquiryStatusTx updates an enquiry's status using q, which may be a transaction
+func UpdateEnquiryStatusTx(q database.Querier, id string, status string) error {
+	_, err := q.Exec(`
 		UPDATE enquiries
 		SET status = $1, updated_at = $2
 		WHERE id = $3
 	`, status, time.Now(), id)
+	return err
+}
 
-	if err != nil {
-		return nil, err
+// decodeQuote decodes the stored quote of an enquiry, which is NULL for enquiries made before quotes were stored
+func decodeQuote(data []byte) (*models.PriceQuote, error) {
+	if data == nil {
+		return nil, nil
 	}
 
-	return GetEnquiryByID(id)
+	var quote models.PriceQuote
+	if err := json.Unmarshal(data, &quote); err != nil {
+		return nil, err
+	}
+	return &quote, nil
 }
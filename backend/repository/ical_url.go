@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+)
+
+// icalURLColumns is the column list read by scanICalURL
+const icalURLColumns = `id, property_id, url, source, last_sync, status, sync_interval_minutes, next_sync_at, consecutive_failures, last_error, etag, last_modified, content_synced_at, created_at, updated_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanICalURL scans a row selected with icalURLColumns
+func scanICalURL(row rowScanner) (models.ICalURL, error) {
+	var u models.ICalURL
+	var source, lastError, etag, lastModified sql.NullString
+	var lastSync, nextSync, contentSynced sql.NullTime
+	err := row.Scan(&u.ID, &u.PropertyID, &u.URL, &source, &lastSync, &u.Status, &u.SyncIntervalMinutes, &nextSync, &u.ConsecutiveFailures, &lastError, &etag, &lastModified, &contentSynced, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return u, err
+	}
+	if source.Valid {
+		u.Source = source.String
+	}
+	if lastSync.Valid {
+		u.LastSync = lastSync.Time
+	}
+	if nextSync.Valid {
+		u.NextSyncAt = nextSync.Time
+	}
+	if lastError.Valid {
+		u.LastError = lastError.String
+	}
+	if etag.Valid {
+		u.ETag = etag.String
+	}
+	if lastModified.Valid {
+		u.LastModified = lastModified.String
+	}
+	if contentSynced.Valid {
+		u.ContentSyncedAt = contentSynced.Time
+	}
+	return u, nil
+}
+
+// queryICalURLs runs a query selecting icalURLColumns and scans every row
+func queryICalURLs(query string, args ...interface{}) ([]models.ICalURL, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []models.ICalURL
+	for rows.Next() {
+		u, err := scanICalURL(rows)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+
+	return urls, nil
+}
+
+// GetAllICalURLs returns all iCal URLs
+func GetAllICalURLs() ([]models.ICalURL, error) {
+	return queryICalURLs(`
+		SELECT ` + icalURLColumns + `
+		FROM ical_urls
+		ORDER BY created_at DESC
+	`)
+}
+
+// GetICalURLsByPropertyID returns iCal URLs for a property
+func GetICalURLsByPropertyID(propertyID string) ([]models.ICalURL, error) {
+	return queryICalURLs(`
+		SELECT `+icalURLColumns+`
+		FROM ical_urls
+		WHERE property_id = $1
+		ORDER BY created_at DESC
+	`, propertyID)
+}
+
+// GetDueICalURLs returns the iCal URLs whose next sync is due, oldest first
+func GetDueICalURLs(now time.Time, limit int) ([]models.ICalURL, error) {
+	return queryICalURLs(`
+		SELECT `+icalURLColumns+`
+		FROM ical_urls
+		WHERE next_sync_at IS NULL OR next_sync_at <= $1
+		ORDER BY next_sync_at ASC NULLS FIRST
+		LIMIT $2
+	`, now, limit)
+}
+
+// GetICalURLByID returns an iCal URL by ID
+func GetICalURLByID(id string) (*models.ICalURL, error) {
+	u, err := scanICalURL(database.DB.QueryRow(`
+		SELECT `+icalURLColumns+`
+		FROM ical_urls
+		WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// CreateICalURL creates a new iCal URL, due for an immediate first sync
+func CreateICalURL(req models.CreateICalURLRequest) (*models.ICalURL, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO ical_urls (id, property_id, url, source, status, sync_interval_minutes, next_sync_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'active', $5, $6, $7, $8)
+	`, id, req.PropertyID, req.URL, req.Source, req.SyncIntervalMinutes, now, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetICalURLByID(id)
+}
+
+// UpdateICalURLInterval changes how often an iCal URL is synced
+func UpdateICalURLInterval(id string, minutes int) (*models.ICalURL, error) {
+	_, err := database.DB.Exec(`
+		UPDATE ical_urls
+		SET sync_interval_minutes = $1, updated_at = $2
+		WHERE id = $3
+	`, minutes, time.Now(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetICalURLByID(id)
+}
+
+// UpdateICalURLStatus updates the status, last error and last sync time of an iCal URL
+func UpdateICalURLStatus(id string, status string, lastError string) error {
+	_, err := database.DB.Exec(`
+		UPDATE ical_urls
+		SET status = $1, last_error = NULLIF($2, ''), last_sync = $3, updated_at = $4
+		WHERE id = $5
+	`, status, lastError, time.Now(), time.Now(), id)
+	return err
+}
+
+// SetICalURLValidators stores the ETag and Last-Modified of the feed content just applied
+func SetICalURLValidators(q database.Querier, id, etag, lastModified string) error {
+	_, err := q.Exec(`
+		UPDATE ical_urls
+		SET etag = NULLIF($1, ''), last_modified = NULLIF($2, ''), content_synced_at = $3
+		WHERE id = $4
+	`, etag, lastModified, time.Now(), id)
+	return err
+}
+
+// CreateICalSyncRun records a sync run and prunes the feed's history down to the newest keep runs
+func CreateICalSyncRun(run models.ICalSyncRun, keep int) error {
+	var httpStatus interface{}
+	if run.HTTPStatus != 0 {
+		httpStatus = run.HTTPStatus
+	}
+
+	_, err := database.DB.Exec(`
+		INSERT INTO ical_sync_runs (id, ical_url_id, started_at, finished_at, outcome, http_status, error_message, events_parsed, events_skipped, dates_added, dates_removed)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, uuid.New().String(), run.ICalURLID, run.StartedAt, run.FinishedAt, run.Outcome, httpStatus, run.ErrorMessage, run.EventsParsed, run.EventsSkipped, run.DatesAdded, run.DatesRemoved)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.DB.Exec(`
+		DELETE FROM ical_sync_runs
+		WHERE ical_url_id = $1 AND id NOT IN (
+			SELECT id FROM ical_sync_runs WHERE ical_url_id = $1 ORDER BY started_at DESC LIMIT $2
+		)
+	`, run.ICalURLID, keep)
+	return err
+}
+
+// GetICalSyncRuns returns the most recent sync runs of an iCal URL, newest first
+func GetICalSyncRuns(icalURLID string, limit int) ([]models.ICalSyncRun, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, ical_url_id, started_at, finished_at, outcome, http_status, error_message, events_parsed, events_skipped, dates_added, dates_removed
+		FROM ical_sync_runs
+		WHERE ical_url_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, icalURLID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []models.ICalSyncRun
+	for rows.Next() {
+		var r models.ICalSyncRun
+		var httpStatus sql.NullInt64
+		var errorMessage sql.NullString
+		err := rows.Scan(&r.ID, &r.ICalURLID, &r.StartedAt, &r.FinishedAt, &r.Outcome, &httpStatus, &errorMessage, &r.EventsParsed, &r.EventsSkipped, &r.DatesAdded, &r.DatesRemoved)
+		if err != nil {
+			return nil, err
+		}
+		if httpStatus.Valid {
+			r.HTTPStatus = int(httpStatus.Int64)
+		}
+		if errorMessage.Valid {
+			r.ErrorMessage = errorMessage.String
+		}
+		runs = append(runs, r)
+	}
+
+	return runs, nil
+}
+
+// ScheduleICalURL sets when an iCal URL is next synced and how many syncs in a row have failed
+func ScheduleICalURL(id string, nextSyncAt time.Time, consecutiveFailures int) error {
+	_, err := database.DB.Exec(`
+		UPDATE ical_urls
+		SET next_sync_at = $1, consecutive_failures = $2
+		WHERE id = $3
+	`, nextSyncAt, consecutiveFailures, id)
+	return err
+}
+
+// MarkICalURLsDue makes every iCal URL due for sync now
+func MarkICalURLsDue() (int, error) {
+	result, err := database.DB.Exec("UPDATE ical_urls SET next_sync_at = $1", time.Now())
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// LockICalURL locks an iCal URL row for the rest of the transaction, so a feed is never synced twice at once.
+// It returns false if the URL no longer exists.
+func LockICalURL(tx *sql.Tx, id string) (bool, error) {
+	var lockedID string
+	err := tx.QueryRow("SELECT id FROM ical_urls WHERE id = $1 FOR UPDATE", id).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteICalURL deletes an iCal URL and the dates it blocked
+func DeleteICalURL(id string) error {
+	_, err := database.DB.Exec("DELETE FROM ical_urls WHERE id = $1", id)
+	return err
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+)
+
+// pricingRuleColumns is the column list read by scanPricingRule
+const pricingRuleColumns = `id, property_id, name, rule_type, min_days_out, max_days_out, min_occupancy, max_occupancy,
+	window_days, max_gap_nights, percent, active, created_at, updated_at`
+
+// scanPricingRule scans a row selected with pricingRuleColumns
+func scanPricingRule(row rowScanner) (models.PricingRule, error) {
+	var r models.PricingRule
+	var minDaysOut, maxDaysOut, minOccupancy, maxOccupancy sql.NullInt64
+	err := row.Scan(&r.ID, &r.PropertyID, &r.Name, &r.Type, &minDaysOut, &maxDaysOut, &minOccupancy, &maxOccupancy,
+		&r.WindowDays, &r.MaxGapNights, &r.Percent, &r.Active, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return r, err
+	}
+	r.MinDaysOut = nullableInt(minDaysOut)
+	r.MaxDaysOut = nullableInt(maxDaysOut)
+	r.MinOccupancy = nullableInt(minOccupancy)
+	r.MaxOccupancy = nullableInt(maxOccupancy)
+	return r, nil
+}
+
+// queryPricingRules runs a query selecting pricingRuleColumns and scans every row
+func queryPricingRules(query string, args ...interface{}) ([]models.PricingRule, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.PricingRule
+	for rows.Next() {
+		r, err := scanPricingRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// GetAllPricingRules returns the pricing rules of every property
+func GetAllPricingRules() ([]models.PricingRule, error) {
+	return queryPricingRules(`
+		SELECT ` + pricingRuleColumns + `
+		FROM pricing_rules
+		ORDER BY property_id, created_at ASC
+	`)
+}
+
+// GetPricingRulesByPropertyID returns the pricing rules of a property in the order they were created
+func GetPricingRulesByPropertyID(propertyID string) ([]models.PricingRule, error) {
+	return queryPricingRules(`
+		SELECT `+pricingRuleColumns+`
+		FROM pricing_rules
+		WHERE property_id = $1
+		ORDER BY created_at ASC
+	`, propertyID)
+}
+
+// GetPricingRuleByID returns a pricing rule by ID
+func GetPricingRuleByID(id string) (*models.PricingRule, error) {
+	r, err := scanPricingRule(database.DB.QueryRow(`
+		SELECT `+pricingRuleColumns+`
+		FROM pricing_rules
+		WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// CreatePricingRule creates a new pricing rule
+func CreatePricingRule(req models.CreatePricingRuleRequest) (*models.PricingRule, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO pricing_rules (id, property_id, name, rule_type, min_days_out, max_days_out, min_occupancy, max_occupancy,
+			window_days, max_gap_nights, percent, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, id, req.PropertyID, req.Name, req.Type, req.MinDaysOut, req.MaxDaysOut, req.MinOccupancy, req.MaxOccupancy,
+		req.WindowDays, req.MaxGapNights, req.Percent, req.Active, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPricingRuleByID(id)
+}
+
+// UpdatePricingRule updates an existing pricing rule
+func UpdatePricingRule(id string, req models.UpdatePricingRuleRequest) (*models.PricingRule, error) {
+	_, err := database.DB.Exec(`
+		UPDATE pricing_rules
+		SET name = $1, rule_type = $2, min_days_out = $3, max_days_out = $4, min_occupancy = $5, max_occupancy = $6,
+			window_days = $7, max_gap_nights = $8, percent = $9, active = $10, updated_at = $11
+		WHERE id = $12
+	`, req.Name, req.Type, req.MinDaysOut, req.MaxDaysOut, req.MinOccupancy, req.MaxOccupancy,
+		req.WindowDays, req.MaxGapNights, req.Percent, req.Active, time.Now(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPricingRuleByID(id)
+}
+
+// DeletePricingRule deletes a pricing rule
+func DeletePricingRule(id string) error {
+	_, err := database.DB.Exec("DELETE FROM pricing_rules WHERE id = $1", id)
+	return err
+}
+
+// nullableInt returns the value of a nullable column, or nil
+func nullableInt(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+)
+
+// promoCodeColumns is the column list read by scanPromoCode, including the number of redemptions
+// by enquiries that are not cancelled
+const promoCodeColumns = `id, property_id, code, name, discount_type, amount,
+	to_char(valid_from, 'YYYY-MM-DD'), to_char(valid_until, 'YYYY-MM-DD'), to_char(stay_from, 'YYYY-MM-DD'), to_char(stay_until, 'YYYY-MM-DD'),
+	min_nights, max_uses, max_uses_per_email, active,
+	(SELECT COUNT(*) FROM promo_redemptions r JOIN enquiries e ON e.id = r.enquiry_id
+		WHERE r.promo_code_id = promo_codes.id AND e.status <> 'cancelled'),
+	created_at, updated_at`
+
+// scanPromoCode scans a row selected with promoCodeColumns
+func scanPromoCode(row rowScanner) (models.PromoCode, error) {
+	var p models.PromoCode
+	var validFrom, validUntil, stayFrom, stayUntil sql.NullString
+	err := row.Scan(&p.ID, &p.PropertyID, &p.Code, &p.Name, &p.Type, &p.Amount,
+		&validFrom, &validUntil, &stayFrom, &stayUntil,
+		&p.MinNights, &p.MaxUses, &p.MaxUsesPerEmail, &p.Active, &p.Redemptions, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return p, err
+	}
+	p.ValidFrom = nullableString(validFrom)
+	p.ValidUntil = nullableString(validUntil)
+	p.StayFrom = nullableString(stayFrom)
+	p.StayUntil = nullableString(stayUntil)
+	return p, nil
+}
+
+// queryPromoCodes runs a query selecting promoCodeColumns and scans every row
+func queryPromoCodes(query string, args ...interface{}) ([]models.PromoCode, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []models.PromoCode
+	for rows.Next() {
+		p, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, p)
+	}
+
+	return codes, nil
+}
+
+// GetAllPromoCodes returns the promo codes of every property
+func GetAllPromoCodes() ([]models.PromoCode, error) {
+	return queryPromoCodes(`
+		SELECT ` + promoCodeColumns + `
+		FROM promo_codes
+		ORDER BY created_at DESC
+	`)
+}
+
+// GetPromoCodesByPropertyID returns the promo codes of a property
+func GetPromoCodesByPropertyID(propertyID string) ([]models.PromoCode, error) {
+	return queryPromoCodes(`
+		SELECT `+promoCodeColumns+`
+		FROM promo_codes
+		WHERE property_id = $1
+		ORDER BY created_at DESC
+	`, propertyID)
+}
+
+// GetPromoCodeByID returns a promo code by ID
+func GetPromoCodeByID(id string) (*models.PromoCode, error) {
+	return getPromoCode(database.DB, `WHERE id = $1`, id)
+}
+
+// GetPromoCodeByCode returns a property's promo code by its code, in any case
+func GetPromoCodeByCode(propertyID, code string) (*models.PromoCode, error) {
+	return getPromoCode(database.DB, `WHERE property_id = $1 AND code = $2`, propertyID, strings.ToUpper(code))
+}
+
+// GetPromoCodeForUpdate returns a property's promo code by its code and locks it for the rest of the
+// transaction, so concurrent enquiries cannot redeem it past its limits
+func GetPromoCodeForUpdate(tx *sql.Tx, propertyID, code string) (*models.PromoCode, error) {
+	return getPromoCode(tx, `WHERE property_id = $1 AND code = $2 FOR UPDATE`, propertyID, strings.ToUpper(code))
+}
+
+// getPromoCode returns the promo code selected by a WHERE clause, or nil
+func getPromoCode(q database.Querier, where string, args ...interface{}) (*models.PromoCode, error) {
+	p, err := scanPromoCode(q.QueryRow(`
+		SELECT `+promoCodeColumns+`
+		FROM promo_codes
+		`+where, args...))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// CreatePromoCode creates a new promo code, active unless the request says otherwise. Its code must
+// already be normalized.
+func CreatePromoCode(req models.CreatePromoCodeRequest) (*models.PromoCode, error) {
+	id := uuid.New().String()
+	now := time.Now()
+	active := req.Active == nil || *req.Active
+
+	_, err := database.DB.Exec(`
+		INSERT INTO promo_codes (id, property_id, code, name, discount_type, amount, valid_from, valid_until, stay_from, stay_until,
+			min_nights, max_uses, max_uses_per_email, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`, id, req.PropertyID, req.Code, req.Name, req.Type, req.Amount, req.ValidFrom, req.ValidUntil, req.StayFrom, req.StayUntil,
+		req.MinNights, req.MaxUses, req.MaxUsesPerEmail, active, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPromoCodeByID(id)
+}
+
+// UpdatePromoCode updates an existing promo code. Its code must already be normalized.
+func UpdatePromoCode(id string, req models.UpdatePromoCodeRequest) (*models.PromoCode, error) {
+	_, err := database.DB.Exec(`
+		UPDATE promo_codes
+		SET code = $1, name = $2, discount_type = $3, amount = $4, valid_from = $5, valid_until = $6, stay_from = $7, stay_until = $8,
+			min_nights = $9, max_uses = $10, max_uses_per_email = $11, active = $12, updated_at = $13
+		WHERE id = $14
+	`, req.Code, req.Name, req.Type, req.Amount, req.ValidFrom, req.ValidUntil, req.StayFrom, req.StayUntil,
+		req.MinNights, req.MaxUses, req.MaxUsesPerEmail, req.Active, time.Now(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPromoCodeByID(id)
+}
+
+// DeletePromoCode deletes a promo code and its redemptions
+func DeletePromoCode(id string) error {
+	_, err := database.DB.Exec("DELETE FROM promo_codes WHERE id = $1", id)
+	return err
+}
+
+// CountPromoRedemptions returns how often a promo code was redeemed by enquiries that are not cancelled,
+// in total and with an email address
+func CountPromoRedemptions(q database.Querier, promoCodeID, email string) (total int, byEmail int, err error) {
+	err = q.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE LOWER(r.email) = LOWER($2))
+		FROM promo_redemptions r
+		JOIN enquiries e ON e.id = r.enquiry_id
+		WHERE r.promo_code_id = $1 AND e.status <> 'cancelled'
+	`, promoCodeID, email).Scan(&total, &byEmail)
+	return total, byEmail, err
+}
+
+// CreatePromoRedemptionTx records that an enquiry redeemed a promo code using q, which may be a transaction
+func CreatePromoRedemptionTx(q database.Querier, promoCodeID, enquiryID, email string, amount models.Money) error {
+	_, err := q.Exec(`
+		INSERT INTO promo_redemptions (id, promo_code_id, enquiry_id, email, amount, currency, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, uuid.New().String(), promoCodeID, enquiryID, email, amount.Decimal(), amount.Currency, time.Now())
+	return err
+}
+
+// GetPromoRedemptions returns the redemptions of a promo code, newest first
+func GetPromoRedemptions(promoCodeID string) ([]models.PromoRedemption, error) {
+	rows, err := database.DB.Query(`
+		SELECT r.id, r.promo_code_id, r.enquiry_id, r.email, r.amount, r.currency, e.status, r.created_at
+		FROM promo_redemptions r
+		JOIN enquiries e ON e.id = r.enquiry_id
+		WHERE r.promo_code_id = $1
+		ORDER BY r.created_at DESC
+	`, promoCodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redemptions []models.PromoRedemption
+	for rows.Next() {
+		var r models.PromoRedemption
+		var amount models.Decimal
+		var currency string
+		if err := rows.Scan(&r.ID, &r.PromoCodeID, &r.EnquiryID, &r.Email, &amount, &currency, &r.EnquiryStatus, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		r.Amount = models.MoneyFromDecimal(amount, currency)
+		r.Currency = currency
+		redemptions = append(redemptions, r)
+	}
+
+	return redemptions, nil
+}
+
+// nullableString returns the value of a nullable column, or nil
+func nullableString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -2,19 +2,27 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 	"villa-arama-riverside/database"
 	"villa-arama-riverside/models"
 
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
-// GetAllProperties returns all properties
-func GetAllProperties() ([]models.Property, error) {
-	rows, err := database.DB.Query(`
-		SELECT id, name, tagline, description, location, image_url, images, amenities, max_guests, bedrooms, bathrooms, created_at, updated_at
-		FROM properties
-		ORDER BY created_at DESC
-	`)
+// propertyColumns is the column list read by scanProperty
+const propertyColumns = `id, name, tagline, description, location, image_url, amenities, max_guests, bedrooms, bathrooms, status, currency, created_at, updated_at`
+
+// scanProperty scans a row selected with propertyColumns
+func scanProperty(row rowScanner) (models.Property, error) {
+	var p models.Property
+	err := row.Scan(&p.ID, &p.Name, &p.Tagline, &p.Description, &p.Location, &p.ImageURL, pq.Array(&p.Amenities), &p.MaxGuests, &p.Bedrooms, &p.Bathrooms, &p.Status, &p.Currency, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+// queryProperties runs a query selecting propertyColumns and scans every row
+func queryProperties(query string, args ...interface{}) ([]models.Property, error) {
+	rows, err := database.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -22,8 +30,7 @@ func GetAllProperties() ([]models.Property, error) {
 
 	var properties []models.Property
 	for rows.Next() {
-		var p models.Property
-		err := rows.Scan(&p.ID, &p.Name, &p.Tagline, &p.Description, &p.Location, &p.ImageURL, pq.Array(&p.Images), pq.Array(&p.Amenities), &p.MaxGuests, &p.Bedrooms, &p.Bathrooms, &p.CreatedAt, &p.UpdatedAt)
+		p, err := scanProperty(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -33,14 +40,32 @@ func GetAllProperties() ([]models.Property, error) {
 	return properties, nil
 }
 
+// GetAllProperties returns all properties, whatever their status
+func GetAllProperties() ([]models.Property, error) {
+	return queryProperties(`
+		SELECT ` + propertyColumns + `
+		FROM properties
+		ORDER BY created_at DESC
+	`)
+}
+
+// GetPublishedProperties returns the properties shown to guests
+func GetPublishedProperties() ([]models.Property, error) {
+	return queryProperties(`
+		SELECT `+propertyColumns+`
+		FROM properties
+		WHERE status = $1
+		ORDER BY created_at DESC
+	`, models.PropertyStatusPublished)
+}
+
 // GetPropertyByID returns a property by ID
 func GetPropertyByID(id string) (*models.Property, error) {
-	var p models.Property
-	err := database.DB.QueryRow(`
-		SELECT id, name, tagline, description, location, image_url, images, amenities, max_guests, bedrooms, bathrooms, created_at, updated_at
+	p, err := scanProperty(database.DB.QueryRow(`
+		SELECT `+propertyColumns+`
 		FROM properties
 		WHERE id = $1
-	`, id).Scan(&p.ID, &p.Name, &p.Tagline, &p.Description, &p.Location, &p.ImageURL, pq.Array(&p.Images), pq.Array(&p.Amenities), &p.MaxGuests, &p.Bedrooms, &p.Bathrooms, &p.CreatedAt, &p.UpdatedAt)
+	`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -51,3 +76,120 @@ func GetPropertyByID(id string) (*models.Property, error) {
 
 	return &p, nil
 }
+
+// CreateProperty creates a new property with its iCal export token
+func CreateProperty(p models.Property, icalExportToken string) (*models.Property, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO properties (id, name, tagline, description, location, image_url, amenities, max_guests, bedrooms, bathrooms, status, currency, ical_export_token, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`, id, p.Name, p.Tagline, p.Description, p.Location, p.ImageURL, pq.Array(p.Amenities), p.MaxGuests, p.Bedrooms, p.Bathrooms, p.Status, p.Currency, icalExportToken, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPropertyByID(id)
+}
+
+// UpdateProperty saves every editable field of a property
+func UpdateProperty(p models.Property) (*models.Property, error) {
+	_, err := database.DB.Exec(`
+		UPDATE properties
+		SET name = $1, tagline = $2, description = $3, location = $4, image_url = $5, amenities = $6,
+			max_guests = $7, bedrooms = $8, bathrooms = $9, status = $10, currency = $11, updated_at = $12
+		WHERE id = $13
+	`, p.Name, p.Tagline, p.Description, p.Location, p.ImageURL, pq.Array(p.Amenities), p.MaxGuests, p.Bedrooms, p.Bathrooms, p.Status, p.Currency, time.Now(), p.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPropertyByID(p.ID)
+}
+
+// CountEnquiriesByPropertyID returns how many enquiries were made for a property
+func CountEnquiriesByPropertyID(propertyID string) (int, error) {
+	var count int
+	err := database.DB.QueryRow("SELECT COUNT(*) FROM enquiries WHERE property_id = $1", propertyID).Scan(&count)
+	return count, err
+}
+
+// PropertyHasPrices reports whether a property has seasons, date price overrides, bedroom configs,
+// or fixed fees or discounts, whose amounts are in the property's currency
+func PropertyHasPrices(propertyID string) (bool, error) {
+	var priced bool
+	err := database.DB.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM seasons WHERE property_id = $1)
+			OR EXISTS (SELECT 1 FROM date_price_overrides WHERE property_id = $1)
+			OR EXISTS (SELECT 1 FROM bedroom_configs WHERE property_id = $1)
+			OR EXISTS (SELECT 1 FROM property_fees WHERE property_id = $1 AND fee_type <> 'percent')
+			OR EXISTS (SELECT 1 FROM stay_discounts WHERE property_id = $1 AND discount_type = 'amount')
+			OR EXISTS (SELECT 1 FROM promo_codes WHERE property_id = $1 AND discount_type = 'amount')
+	`, propertyID).Scan(&priced)
+	return priced, err
+}
+
+// DeleteProperty deletes a property with its calendar data; seasons and bedroom configs cascade
+func DeleteProperty(tx *sql.Tx, id string) error {
+	if _, err := tx.Exec("DELETE FROM blocked_dates WHERE property_id = $1", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM ical_urls WHERE property_id = $1", id); err != nil {
+		return err
+	}
+	_, err := tx.Exec("DELETE FROM properties WHERE id = $1", id)
+	invalidateSeasonCache()
+	return err
+}
+
+// LockProperty takes a row lock on a property for the rest of the transaction, serialising bookings for it
+func LockProperty(tx *sql.Tx, id string) error {
+	var lockedID string
+	return tx.QueryRow("SELECT id FROM properties WHERE id = $1 FOR UPDATE", id).Scan(&lockedID)
+}
+
+// GetICalExportToken returns the iCal export token of a property, or an empty string if it has none
+func GetICalExportToken(propertyID string) (string, error) {
+	var token sql.NullString
+	err := database.DB.QueryRow("SELECT ical_export_token FROM properties WHERE id = $1", propertyID).Scan(&token)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return token.String, nil
+}
+
+// SetICalExportToken sets the iCal export token of a property
+func SetICalExportToken(propertyID, token string) error {
+	_, err := database.DB.Exec(`
+		UPDATE properties
+		SET ical_export_token = $1, updated_at = $2
+		WHERE id = $3
+	`, token, time.Now(), propertyID)
+	return err
+}
+
+// GetPropertyIDsWithoutICalExportToken returns the IDs of properties that have no iCal export token yet
+func GetPropertyIDsWithoutICalExportToken() ([]string, error) {
+	rows, err := database.DB.Query("SELECT id FROM properties WHERE ical_export_token IS NULL")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
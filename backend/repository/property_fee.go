@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+)
+
+// propertyFeeColumns is the column list read by scanPropertyFee
+const propertyFeeColumns = `id, property_id, name, fee_type, amount, included_guests, taxable, created_at, updated_at`
+
+// scanPropertyFee scans a row selected with propertyFeeColumns
+func scanPropertyFee(row rowScanner) (models.PropertyFee, error) {
+	var f models.PropertyFee
+	err := row.Scan(&f.ID, &f.PropertyID, &f.Name, &f.Type, &f.Amount, &f.IncludedGuests, &f.Taxable, &f.CreatedAt, &f.UpdatedAt)
+	return f, err
+}
+
+// queryPropertyFees runs a query selecting propertyFeeColumns and scans every row
+func queryPropertyFees(query string, args ...interface{}) ([]models.PropertyFee, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fees []models.PropertyFee
+	for rows.Next() {
+		f, err := scanPropertyFee(rows)
+		if err != nil {
+			return nil, err
+		}
+		fees = append(fees, f)
+	}
+
+	return fees, nil
+}
+
+// GetAllPropertyFees returns the fees and taxes of every property in the order they were added
+func GetAllPropertyFees() ([]models.PropertyFee, error) {
+	return queryPropertyFees(`
+		SELECT ` + propertyFeeColumns + `
+		FROM property_fees
+		ORDER BY property_id, created_at ASC
+	`)
+}
+
+// GetPropertyFeesByPropertyID returns the fees and taxes of a property in the order they were added
+func GetPropertyFeesByPropertyID(propertyID string) ([]models.PropertyFee, error) {
+	return queryPropertyFees(`
+		SELECT `+propertyFeeColumns+`
+		FROM property_fees
+		WHERE property_id = $1
+		ORDER BY created_at ASC
+	`, propertyID)
+}
+
+// GetPropertyFeeByID returns a property fee by ID
+func GetPropertyFeeByID(id string) (*models.PropertyFee, error) {
+	f, err := scanPropertyFee(database.DB.QueryRow(`
+		SELECT `+propertyFeeColumns+`
+		FROM property_fees
+		WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// CreatePropertyFee creates a new property fee
+func CreatePropertyFee(req models.CreatePropertyFeeRequest) (*models.PropertyFee, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO property_fees (id, property_id, name, fee_type, amount, included_guests, taxable, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, id, req.PropertyID, req.Name, req.Type, req.Amount, req.IncludedGuests, req.Taxable, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPropertyFeeByID(id)
+}
+
+// UpdatePropertyFee updates an existing property fee
+func UpdatePropertyFee(id string, req models.UpdatePropertyFeeRequest) (*models.PropertyFee, error) {
+	_, err := database.DB.Exec(`
+		UPDATE property_fees
+		SET name = $1, fee_type = $2, amount = $3, included_guests = $4, taxable = $5, updated_at = $6
+		WHERE id = $7
+	`, req.Name, req.Type, req.Amount, req.IncludedGuests, req.Taxable, time.Now(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPropertyFeeByID(id)
+}
+
+// DeletePropertyFee deletes a property fee
+func DeletePropertyFee(id string) error {
+	_, err := database.DB.Exec("DELETE FROM property_fees WHERE id = $1", id)
+	return err
+}
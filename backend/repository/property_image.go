@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// propertyImageColumns is the column list read by scanPropertyImage
+const propertyImageColumns = `id, property_id, storage_key, external_url, content_type, width, height, size_bytes, caption, alt_text, sort_order, created_at, updated_at`
+
+// scanPropertyImage scans a row selected with propertyImageColumns.
+// URL is set for external images only; uploaded images get their URLs from the storage backend.
+func scanPropertyImage(row rowScanner) (models.PropertyImage, error) {
+	var img models.PropertyImage
+	var storageKey, externalURL, contentType sql.NullString
+	var width, height, size sql.NullInt64
+	err := row.Scan(&img.ID, &img.PropertyID, &storageKey, &externalURL, &contentType, &width, &height, &size, &img.Caption, &img.AltText, &img.SortOrder, &img.CreatedAt, &img.UpdatedAt)
+	if err != nil {
+		return img, err
+	}
+	img.StorageKey = storageKey.String
+	img.URL = externalURL.String
+	img.ContentType = contentType.String
+	img.Width = int(width.Int64)
+	img.Height = int(height.Int64)
+	img.SizeBytes = size.Int64
+	return img, nil
+}
+
+// GetPropertyImages returns the images of the given properties in display order, keyed by property ID
+func GetPropertyImages(propertyIDs []string) (map[string][]models.PropertyImage, error) {
+	rows, err := database.DB.Query(`
+		SELECT `+propertyImageColumns+`
+		FROM property_images
+		WHERE property_id = ANY($1)
+		ORDER BY sort_order ASC, created_at ASC
+	`, pq.Array(propertyIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := map[string][]models.PropertyImage{}
+	for rows.Next() {
+		img, err := scanPropertyImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images[img.PropertyID] = append(images[img.PropertyID], img)
+	}
+
+	return images, nil
+}
+
+// GetPropertyImageByID returns an image by ID
+func GetPropertyImageByID(id string) (*models.PropertyImage, error) {
+	img, err := scanPropertyImage(database.DB.QueryRow(`
+		SELECT `+propertyImageColumns+`
+		FROM property_images
+		WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &img, nil
+}
+
+// CreatePropertyImage adds an image after the existing images of its property.
+// An uploaded image has a StorageKey, an external one only a URL.
+func CreatePropertyImage(img models.PropertyImage) (*models.PropertyImage, error) {
+	if img.ID == "" {
+		img.ID = uuid.New().String()
+	}
+	now := time.Now()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO property_images (id, property_id, storage_key, external_url, content_type, width, height, size_bytes, caption, alt_text, sort_order, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, 0), NULLIF($7, 0), NULLIF($8, 0), $9, $10,
+			(SELECT COALESCE(MAX(sort_order) + 1, 0) FROM property_images WHERE property_id = $2), $11, $12)
+	`, img.ID, img.PropertyID, img.StorageKey, img.URL, img.ContentType, img.Width, img.Height, img.SizeBytes, img.Caption, img.AltText, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPropertyImageByID(img.ID)
+}
+
+// UpdatePropertyImage updates the caption and alt text of an image
+func UpdatePropertyImage(id, caption, altText string) (*models.PropertyImage, error) {
+	_, err := database.DB.Exec(`
+		UPDATE property_images
+		SET caption = $1, alt_text = $2, updated_at = $3
+		WHERE id = $4
+	`, caption, altText, time.Now(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetPropertyImageByID(id)
+}
+
+// SetPropertyImageOrder sets the sort order of a property's images to their position in ids
+func SetPropertyImageOrder(tx *sql.Tx, propertyID string, ids []string) error {
+	_, err := tx.Exec(`
+		UPDATE property_images i
+		SET sort_order = o.position - 1, updated_at = $3
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position)
+		WHERE i.id = o.id AND i.property_id = $1
+	`, propertyID, pq.Array(ids), time.Now())
+	return err
+}
+
+// GetPropertyImageIDs returns the IDs of a property's images, locking them for the rest of the transaction
+func GetPropertyImageIDs(tx *sql.Tx, propertyID string) ([]string, error) {
+	rows, err := tx.Query("SELECT id FROM property_images WHERE property_id = $1 FOR UPDATE", propertyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// DeletePropertyImage deletes an image
+func DeletePropertyImage(id string) error {
+	_, err := database.DB.Exec("DELETE FROM property_images WHERE id = $1", id)
+	return err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+)
+
+// quoteColumns is the column list read by scanQuote
+const quoteColumns = `id, property_id, to_char(check_in, 'YYYY-MM-DD'), to_char(check_out, 'YYYY-MM-DD'), bedroom_config_id,
+	quote, expires_at, enquiry_id, created_at`
+
+// scanQuote scans a row selected with quoteColumns
+func scanQuote(row rowScanner) (models.Quote, error) {
+	var q models.Quote
+	var bedroomConfigID, enquiryID sql.NullString
+	var quote []byte
+	err := row.Scan(&q.ID, &q.PropertyID, &q.CheckIn, &q.CheckOut, &bedroomConfigID, &quote, &q.ExpiresAt, &enquiryID, &q.CreatedAt)
+	if err != nil {
+		return q, err
+	}
+	q.BedroomConfigID = bedroomConfigID.String
+	q.EnquiryID = nullableString(enquiryID)
+	err = json.Unmarshal(quote, &q.PriceQuote)
+	return q, err
+}
+
+// GetQuoteByID returns a saved quote by ID
+func GetQuoteByID(id string) (*models.Quote, error) {
+	q, err := scanQuote(database.DB.QueryRow(`
+		SELECT `+quoteColumns+`
+		FROM quotes
+		WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// GetQuoteForUpdate returns a saved quote by ID and locks it for the rest of the transaction
+func GetQuoteForUpdate(tx *sql.Tx, id string) (*models.Quote, error) {
+	q, err := scanQuote(tx.QueryRow(`
+		SELECT `+quoteColumns+`
+		FROM quotes
+		WHERE id = $1
+		FOR UPDATE
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &q, nil
+}
+
+// CreateQuote saves a price quote for a stay, valid until expiresAt
+func CreateQuote(propertyID, checkIn, checkOut, bedroomConfigID string, quote *models.PriceQuote, expiresAt time.Time) (*models.Quote, error) {
+	id := uuid.New().String()
+
+	quoteJSON, err := json.Marshal(quote)
+	if err != nil {
+		return nil, err
+	}
+
+	var bedroomConfig interface{}
+	if bedroomConfigID != "" {
+		bedroomConfig = bedroomConfigID
+	}
+
+	_, err = database.DB.Exec(`
+		INSERT INTO quotes (id, property_id, check_in, check_out, bedroom_config_id, quote, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, id, propertyID, checkIn, checkOut, bedroomConfig, quoteJSON, expiresAt, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetQuoteByID(id)
+}
+
+// UseQuoteTx records that an enquiry used a saved quote, using q, which may be a transaction
+func UseQuoteTx(q database.Querier, id, enquiryID string) error {
+	_, err := q.Exec("UPDATE quotes SET enquiry_id = $1 WHERE id = $2", enquiryID, id)
+	return err
+}
+
+// DeleteExpiredQuotes removes quotes that expired without being used
+func DeleteExpiredQuotes() error {
+	_, err := database.DB.Exec("DELETE FROM quotes WHERE expires_at <= $1 AND enquiry_id IS NULL", time.Now())
+	return err
+}
@@ -7,15 +7,38 @@ import (
 	"villa-arama-riverside/models"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-// GetAllSeasons returns all seasons
-func GetAllSeasons() ([]models.Season, error) {
-	rows, err := database.DB.Query(`
-		SELECT id, name, start_date, end_date, daily_price, is_default, created_at, updated_at
-		FROM seasons
-		ORDER BY is_default DESC, daily_price DESC
-	`)
+// seasonColumns is the column list read by scanSeason
+const seasonColumns = `id, property_id, name, start_date, end_date, start_year, end_year, priority, min_nights, max_nights, closed_to_arrival, closed_to_departure, daily_price, is_default, created_at, updated_at`
+
+// scanSeason scans a row selected with seasonColumns
+func scanSeason(row rowScanner) (models.Season, error) {
+	var s models.Season
+	var startYear, endYear sql.NullInt64
+	var closedToArrival, closedToDeparture pq.Int64Array
+	err := row.Scan(&s.ID, &s.PropertyID, &s.Name, &s.StartDate, &s.EndDate, &startYear, &endYear, &s.Priority, &s.MinNights, &s.MaxNights,
+		&closedToArrival, &closedToDeparture, &s.DailyPrice, &s.IsDefault, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return s, err
+	}
+	s.ClosedToArrival = scanWeekdays(closedToArrival)
+	s.ClosedToDeparture = scanWeekdays(closedToDeparture)
+	if startYear.Valid {
+		year := int(startYear.Int64)
+		s.StartYear = &year
+	}
+	if endYear.Valid {
+		year := int(endYear.Int64)
+		s.EndYear = &year
+	}
+	return s, nil
+}
+
+// querySeasons runs a query selecting seasonColumns and scans every row
+func querySeasons(query string, args ...interface{}) ([]models.Season, error) {
+	rows, err := database.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -23,25 +46,45 @@ func GetAllSeasons() ([]models.Season, error) {
 
 	var seasons []models.Season
 	for rows.Next() {
-		var s models.Season
-		err := rows.Scan(&s.ID, &s.Name, &s.StartDate, &s.EndDate, &s.DailyPrice, &s.IsDefault, &s.CreatedAt, &s.UpdatedAt)
+		s, err := scanSeason(rows)
 		if err != nil {
 			return nil, err
 		}
 		seasons = append(seasons, s)
 	}
 
+	if err := attachWeekdayAdjustments(seasons); err != nil {
+		return nil, err
+	}
 	return seasons, nil
 }
 
+// GetAllSeasons returns all seasons
+func GetAllSeasons() ([]models.Season, error) {
+	return querySeasons(`
+		SELECT ` + seasonColumns + `
+		FROM seasons
+		ORDER BY is_default DESC, priority DESC, start_date ASC
+	`)
+}
+
+// GetSeasonsByPropertyID returns the seasons of a property
+func GetSeasonsByPropertyID(propertyID string) ([]models.Season, error) {
+	return querySeasons(`
+		SELECT `+seasonColumns+`
+		FROM seasons
+		WHERE property_id = $1
+		ORDER BY is_default DESC, priority DESC, start_date ASC
+	`, propertyID)
+}
+
 // GetSeasonByID returns a season by ID
 func GetSeasonByID(id string) (*models.Season, error) {
-	var s models.Season
-	err := database.DB.QueryRow(`
-		SELECT id, name, start_date, end_date, daily_price, is_default, created_at, updated_at
+	s, err := scanSeason(database.DB.QueryRow(`
+		SELECT `+seasonColumns+`
 		FROM seasons
 		WHERE id = $1
-	`, id).Scan(&s.ID, &s.Name, &s.StartDate, &s.EndDate, &s.DailyPrice, &s.IsDefault, &s.CreatedAt, &s.UpdatedAt)
+	`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -50,37 +93,20 @@ func GetSeasonByID(id string) (*models.Season, error) {
 		return nil, err
 	}
 
-	return &s, nil
+	seasons := []models.Season{s}
+	if err := attachWeekdayAdjustments(seasons); err != nil {
+		return nil, err
+	}
+	return &seasons[0], nil
 }
 
-// GetSeasonForDate returns the season for a specific date
-func GetSeasonForDate(date time.Time) (*models.Season, error) {
-	monthDay := date.Format("01-02")
-
-	// First try to find a matching non-default season
-	var s models.Season
-	err := database.DB.QueryRow(`
-		SELECT id, name, start_date, end_date, daily_price, is_default, created_at, updated_at
+// GetDefaultSeason returns the default season of a property
+func GetDefaultSeason(propertyID string) (*models.Season, error) {
+	s, err := scanSeason(database.DB.QueryRow(`
+		SELECT `+seasonColumns+`
 		FROM seasons
-		WHERE is_default = false
-		AND (
-			(start_date <= end_date AND $1 >= start_date AND $1 <= end_date)
-			OR
-			(start_date > end_date AND ($1 >= start_date OR $1 <= end_date))
-		)
-		ORDER BY daily_price DESC
-		LIMIT 1
-	`, monthDay).Scan(&s.ID, &s.Name, &s.StartDate, &s.EndDate, &s.DailyPrice, &s.IsDefault, &s.CreatedAt, &s.UpdatedAt)
-
-	if err == sql.ErrNoRows {
-		// Fall back to default season
-		err = database.DB.QueryRow(`
-			SELECT id, name, start_date, end_date, daily_price, is_default, created_at, updated_at
-			FROM seasons
-			WHERE is_default = true
-			LIMIT 1
-		`).Scan(&s.ID, &s.Name, &s.StartDate, &s.EndDate, &s.DailyPrice, &s.IsDefault, &s.CreatedAt, &s.UpdatedAt)
-	}
+		WHERE property_id = $1 AND is_default
+	`, propertyID))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -89,7 +115,11 @@ func GetSeasonForDate(date time.Time) (*models.Season, error) {
 		return nil, err
 	}
 
-	return &s, nil
+	seasons := []models.Season{s}
+	if err := attachWeekdayAdjustments(seasons); err != nil {
+		return nil, err
+	}
+	return &seasons[0], nil
 }
 
 // CreateSeason creates a new season
@@ -97,29 +127,47 @@ func CreateSeason(req models.CreateSeasonRequest) (*models.Season, error) {
 	id := uuid.New().String()
 	now := time.Now()
 
-	_, err := database.DB.Exec(`
-		INSERT INTO seasons (id, name, start_date, end_date, daily_price, is_default, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`, id, req.Name, req.StartDate, req.EndDate, req.DailyPrice, req.IsDefault, now, now)
+	err := database.WithTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO seasons (id, property_id, name, start_date, end_date, start_year, end_year, priority, min_nights, max_nights,
+				closed_to_arrival, closed_to_departure, daily_price, is_default, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		`, id, req.PropertyID, req.Name, req.StartDate, req.EndDate, req.StartYear, req.EndYear, req.Priority, req.MinNights, req.MaxNights,
+			weekdayArray(req.ClosedToArrival), weekdayArray(req.ClosedToDeparture), req.DailyPrice, req.IsDefault, now, now)
+		if err != nil {
+			return err
+		}
 
+		return setWeekdayAdjustments(tx, id, req.WeekdayAdjustments)
+	})
 	if err != nil {
 		return nil, err
 	}
+	invalidateSeasonCache()
 
 	return GetSeasonByID(id)
 }
 
 // UpdateSeason updates an existing season
 func UpdateSeason(id string, req models.UpdateSeasonRequest) (*models.Season, error) {
-	_, err := database.DB.Exec(`
-		UPDATE seasons
-		SET name = $1, start_date = $2, end_date = $3, daily_price = $4, is_default = $5, updated_at = $6
-		WHERE id = $7
-	`, req.Name, req.StartDate, req.EndDate, req.DailyPrice, req.IsDefault, time.Now(), id)
+	err := database.WithTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE seasons
+			SET name = $1, start_date = $2, end_date = $3, start_year = $4, end_year = $5, priority = $6,
+				min_nights = $7, max_nights = $8, closed_to_arrival = $9, closed_to_departure = $10, daily_price = $11, is_default = $12, updated_at = $13
+			WHERE id = $14
+		`, req.Name, req.StartDate, req.EndDate, req.StartYear, req.EndYear, req.Priority, req.MinNights, req.MaxNights,
+			weekdayArray(req.ClosedToArrival), weekdayArray(req.ClosedToDeparture), req.DailyPrice, req.IsDefault, time.Now(), id)
+		if err != nil {
+			return err
+		}
 
+		return setWeekdayAdjustments(tx, id, req.WeekdayAdjustments)
+	})
 	if err != nil {
 		return nil, err
 	}
+	invalidateSeasonCache()
 
 	return GetSeasonByID(id)
 }
@@ -127,5 +175,65 @@ func UpdateSeason(id string, req models.UpdateSeasonRequest) (*models.Season, er
 // DeleteSeason deletes a season
 func DeleteSeason(id string) error {
 	_, err := database.DB.Exec("DELETE FROM seasons WHERE id = $1", id)
+	invalidateSeasonCache()
 	return err
 }
+
+// attachWeekdayAdjustments loads the weekday adjustments of the given seasons, ordered by weekday
+func attachWeekdayAdjustments(seasons []models.Season) error {
+	if len(seasons) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(seasons))
+	for i := range seasons {
+		ids[i] = seasons[i].ID
+		seasons[i].WeekdayAdjustments = []models.WeekdayAdjustment{}
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT season_id, weekday, adjustment_type, amount
+		FROM season_weekday_adjustments
+		WHERE season_id::text = ANY($1)
+		ORDER BY weekday ASC
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	adjustments := map[string][]models.WeekdayAdjustment{}
+	for rows.Next() {
+		var seasonID string
+		var a models.WeekdayAdjustment
+		if err := rows.Scan(&seasonID, &a.Weekday, &a.Type, &a.Amount); err != nil {
+			return err
+		}
+		adjustments[seasonID] = append(adjustments[seasonID], a)
+	}
+
+	for i := range seasons {
+		if list, ok := adjustments[seasons[i].ID]; ok {
+			seasons[i].WeekdayAdjustments = list
+		}
+	}
+	return nil
+}
+
+// setWeekdayAdjustments replaces the weekday adjustments of a season
+func setWeekdayAdjustments(tx *sql.Tx, seasonID string, adjustments []models.WeekdayAdjustment) error {
+	if _, err := tx.Exec("DELETE FROM season_weekday_adjustments WHERE season_id = $1", seasonID); err != nil {
+		return err
+	}
+
+	for _, a := range adjustments {
+		_, err := tx.Exec(`
+			INSERT INTO season_weekday_adjustments (season_id, weekday, adjustment_type, amount)
+			VALUES ($1, $2, $3, $4)
+		`, seasonID, a.Weekday, a.Type, a.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
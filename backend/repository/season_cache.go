@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"sync"
+	"time"
+	"villa-arama-riverside/models"
+)
+
+// seasonCacheTTL bounds how long cached seasons are used. Changes made through this process clear
+// the cache at once; the TTL picks up changes made by other instances sharing the database.
+const seasonCacheTTL = 5 * time.Minute
+
+// PricingSeasons are the seasons, with their weekday adjustments, and the date price overrides of a property
+type PricingSeasons struct {
+	Seasons   []models.Season
+	Overrides []models.DatePriceOverride
+	loadedAt  time.Time
+}
+
+// seasonCache holds PricingSeasons by property ID. generation changes on every invalidation, so a
+// load that overlaps a change is not stored.
+var seasonCache = struct {
+	sync.Mutex
+	entries    map[string]*PricingSeasons
+	generation uint64
+}{entries: map[string]*PricingSeasons{}}
+
+// GetPricingSeasons returns the seasons and date price overrides of a property from the cache, loading
+// them when missing or expired. The result is shared between callers and must not be modified.
+func GetPricingSeasons(propertyID string) (*PricingSeasons, error) {
+	cached, generation := cachedPricingSeasons(propertyID)
+	if cached != nil {
+		return cached, nil
+	}
+
+	seasons, err := GetSeasonsByPropertyID(propertyID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := GetDatePriceOverridesByPropertyID(propertyID)
+	if err != nil {
+		return nil, err
+	}
+	loaded := &PricingSeasons{Seasons: seasons, Overrides: overrides, loadedAt: time.Now()}
+	storePricingSeasons(propertyID, generation, loaded)
+
+	return loaded, nil
+}
+
+// cachedPricingSeasons returns the unexpired cached seasons of a property, or nil, and the
+// generation to load them at
+func cachedPricingSeasons(propertyID string) (*PricingSeasons, uint64) {
+	seasonCache.Lock()
+	defer seasonCache.Unlock()
+
+	cached := seasonCache.entries[propertyID]
+	if cached != nil && time.Since(cached.loadedAt) >= seasonCacheTTL {
+		cached = nil
+	}
+	return cached, seasonCache.generation
+}
+
+// storePricingSeasons caches seasons loaded at a generation, unless the cache was invalidated since
+func storePricingSeasons(propertyID string, generation uint64, loaded *PricingSeasons) {
+	seasonCache.Lock()
+	defer seasonCache.Unlock()
+
+	if seasonCache.generation == generation {
+		seasonCache.entries[propertyID] = loaded
+	}
+}
+
+// invalidateSeasonCache drops every cached property, after seasons or date price overrides change
+func invalidateSeasonCache() {
+	seasonCache.Lock()
+	seasonCache.entries = map[string]*PricingSeasons{}
+	seasonCache.generation++
+	seasonCache.Unlock()
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeasonCacheStoresAtCurrentGeneration(t *testing.T) {
+	invalidateSeasonCache()
+
+	cached, generation := cachedPricingSeasons("villa")
+	if cached != nil {
+		t.Fatalf("empty cache returned %+v", cached)
+	}
+
+	loaded := &PricingSeasons{loadedAt: time.Now()}
+	storePricingSeasons("villa", generation, loaded)
+
+	if cached, _ := cachedPricingSeasons("villa"); cached != loaded {
+		t.Fatalf("cached %p, want %p", cached, loaded)
+	}
+}
+
+func TestInvalidateSeasonCache(t *testing.T) {
+	invalidateSeasonCache()
+	_, before := cachedPricingSeasons("villa")
+	storePricingSeasons("villa", before, &PricingSeasons{loadedAt: time.Now()})
+
+	invalidateSeasonCache()
+
+	cached, after := cachedPricingSeasons("villa")
+	if cached != nil {
+		t.Errorf("invalidated cache returned %+v", cached)
+	}
+	if after != before+1 {
+		t.Errorf("generation %d after invalidating at %d, want %d", after, before, before+1)
+	}
+}
+
+func TestSeasonCacheDropsLoadsOverlappingInvalidation(t *testing.T) {
+	invalidateSeasonCache()
+	_, generation := cachedPricingSeasons("villa")
+
+	// Seasons change while the load is running
+	invalidateSeasonCache()
+	storePricingSeasons("villa", generation, &PricingSeasons{loadedAt: time.Now()})
+
+	if cached, _ := cachedPricingSeasons("villa"); cached != nil {
+		t.Fatalf("stale load was cached: %+v", cached)
+	}
+}
+
+func TestSeasonCacheExpires(t *testing.T) {
+	invalidateSeasonCache()
+	_, generation := cachedPricingSeasons("villa")
+	storePricingSeasons("villa", generation, &PricingSeasons{loadedAt: time.Now().Add(-seasonCacheTTL)})
+
+	if cached, _ := cachedPricingSeasons("villa"); cached != nil {
+		t.Fatalf("expired seasons returned: %+v", cached)
+	}
+}
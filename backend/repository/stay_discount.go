@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+)
+
+// stayDiscountColumns is the column list read by scanStayDiscount
+const stayDiscountColumns = `id, property_id, name, min_nights, discount_type, amount, created_at, updated_at`
+
+// scanStayDiscount scans a row selected with stayDiscountColumns
+func scanStayDiscount(row rowScanner) (models.StayDiscount, error) {
+	var d models.StayDiscount
+	err := row.Scan(&d.ID, &d.PropertyID, &d.Name, &d.MinNights, &d.Type, &d.Amount, &d.CreatedAt, &d.UpdatedAt)
+	return d, err
+}
+
+// queryStayDiscounts runs a query selecting stayDiscountColumns and scans every row
+func queryStayDiscounts(query string, args ...interface{}) ([]models.StayDiscount, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var discounts []models.StayDiscount
+	for rows.Next() {
+		d, err := scanStayDiscount(rows)
+		if err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, d)
+	}
+
+	return discounts, nil
+}
+
+// GetAllStayDiscounts returns the stay discounts of every property by minimum nights
+func GetAllStayDiscounts() ([]models.StayDiscount, error) {
+	return queryStayDiscounts(`
+		SELECT ` + stayDiscountColumns + `
+		FROM stay_discounts
+		ORDER BY property_id, min_nights ASC
+	`)
+}
+
+// GetStayDiscountsByPropertyID returns the stay discounts of a property by minimum nights
+func GetStayDiscountsByPropertyID(propertyID string) ([]models.StayDiscount, error) {
+	return queryStayDiscounts(`
+		SELECT `+stayDiscountColumns+`
+		FROM stay_discounts
+		WHERE property_id = $1
+		ORDER BY min_nights ASC
+	`, propertyID)
+}
+
+// GetStayDiscountByID returns a stay discount by ID
+func GetStayDiscountByID(id string) (*models.StayDiscount, error) {
+	d, err := scanStayDiscount(database.DB.QueryRow(`
+		SELECT `+stayDiscountColumns+`
+		FROM stay_discounts
+		WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// CreateStayDiscount creates a new stay discount
+func CreateStayDiscount(req models.CreateStayDiscountRequest) (*models.StayDiscount, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO stay_discounts (id, property_id, name, min_nights, discount_type, amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, id, req.PropertyID, req.Name, req.MinNights, req.Type, req.Amount, now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetStayDiscountByID(id)
+}
+
+// UpdateStayDiscount updates an existing stay discount
+func UpdateStayDiscount(id string, req models.UpdateStayDiscountRequest) (*models.StayDiscount, error) {
+	_, err := database.DB.Exec(`
+		UPDATE stay_discounts
+		SET name = $1, min_nights = $2, discount_type = $3, amount = $4, updated_at = $5
+		WHERE id = $6
+	`, req.Name, req.MinNights, req.Type, req.Amount, time.Now(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetStayDiscountByID(id)
+}
+
+// DeleteStayDiscount deletes a stay discount
+func DeleteStayDiscount(id string) error {
+	_, err := database.DB.Exec("DELETE FROM stay_discounts WHERE id = $1", id)
+	return err
+}
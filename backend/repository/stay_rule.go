@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// stayRuleColumns is the column list read by scanStayRule
+const stayRuleColumns = `id, property_id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), min_nights, max_nights, closed_to_arrival, closed_to_departure, created_at, updated_at`
+
+// scanStayRule scans a row selected with stayRuleColumns
+func scanStayRule(row rowScanner) (models.StayRule, error) {
+	var r models.StayRule
+	var startDate, endDate sql.NullString
+	var closedToArrival, closedToDeparture pq.Int64Array
+	err := row.Scan(&r.ID, &r.PropertyID, &r.Name, &startDate, &endDate, &r.MinNights, &r.MaxNights, &closedToArrival, &closedToDeparture, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return r, err
+	}
+	r.ClosedToArrival = scanWeekdays(closedToArrival)
+	r.ClosedToDeparture = scanWeekdays(closedToDeparture)
+	if startDate.Valid && endDate.Valid {
+		r.StartDate = &startDate.String
+		r.EndDate = &endDate.String
+	}
+	return r, nil
+}
+
+// queryStayRules runs a query selecting stayRuleColumns and scans every row
+func queryStayRules(query string, args ...interface{}) ([]models.StayRule, error) {
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []models.StayRule
+	for rows.Next() {
+		r, err := scanStayRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// GetAllStayRules returns the stay rules of every property
+func GetAllStayRules() ([]models.StayRule, error) {
+	return queryStayRules(`
+		SELECT ` + stayRuleColumns + `
+		FROM stay_rules
+		ORDER BY start_date ASC NULLS FIRST
+	`)
+}
+
+// GetStayRulesByPropertyID returns the stay rules of a property
+func GetStayRulesByPropertyID(propertyID string) ([]models.StayRule, error) {
+	return queryStayRules(`
+		SELECT `+stayRuleColumns+`
+		FROM stay_rules
+		WHERE property_id = $1
+		ORDER BY start_date ASC NULLS FIRST
+	`, propertyID)
+}
+
+// GetStayRuleByID returns a stay rule by ID
+func GetStayRuleByID(id string) (*models.StayRule, error) {
+	r, err := scanStayRule(database.DB.QueryRow(`
+		SELECT `+stayRuleColumns+`
+		FROM stay_rules
+		WHERE id = $1
+	`, id))
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// CreateStayRule creates a new stay rule
+func CreateStayRule(req models.CreateStayRuleRequest) (*models.StayRule, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO stay_rules (id, property_id, name, start_date, end_date, min_nights, max_nights, closed_to_arrival, closed_to_departure, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, id, req.PropertyID, req.Name, req.StartDate, req.EndDate, req.MinNights, req.MaxNights,
+		weekdayArray(req.ClosedToArrival), weekdayArray(req.ClosedToDeparture), now, now)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetStayRuleByID(id)
+}
+
+// UpdateStayRule updates an existing stay rule
+func UpdateStayRule(id string, req models.UpdateStayRuleRequest) (*models.StayRule, error) {
+	_, err := database.DB.Exec(`
+		UPDATE stay_rules
+		SET name = $1, start_date = $2, end_date = $3, min_nights = $4, max_nights = $5,
+			closed_to_arrival = $6, closed_to_departure = $7, updated_at = $8
+		WHERE id = $9
+	`, req.Name, req.StartDate, req.EndDate, req.MinNights, req.MaxNights,
+		weekdayArray(req.ClosedToArrival), weekdayArray(req.ClosedToDeparture), time.Now(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return GetStayRuleByID(id)
+}
+
+// DeleteStayRule deletes a stay rule
+func DeleteStayRule(id string) error {
+	_, err := database.DB.Exec("DELETE FROM stay_rules WHERE id = $1", id)
+	return err
+}
+
+// scanWeekdays converts a scanned weekday array to a list that is never nil
+func scanWeekdays(a pq.Int64Array) []int {
+	days := make([]int, len(a))
+	for i, d := range a {
+		days[i] = int(d)
+	}
+	return days
+}
+
+// weekdayArray converts a weekday list to an array parameter, storing nil as an empty array
+func weekdayArray(days []int) pq.Int64Array {
+	a := make(pq.Int64Array, len(days))
+	for i, d := range days {
+		a[i] = int64(d)
+	}
+	return a
+}
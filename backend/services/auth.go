@@ -0,0 +1,139 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SessionTTL is how long an admin session stays valid after login
+const SessionTTL = 7 * 24 * time.Hour
+
+// MinPasswordLength is the minimum length of an admin password
+const MinPasswordLength = 10
+
+// ErrInvalidCredentials is returned when the email or password is wrong
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// dummyPasswordHash is compared against when the email is unknown
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
+// HashPassword hashes a password with bcrypt
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// Login verifies the credentials and creates a new session, returning its token
+func Login(email, password string) (string, *models.AdminUser, error) {
+	user, err := repository.GetAdminUserByEmail(strings.TrimSpace(email))
+	if err != nil {
+		return "", nil, err
+	}
+
+	if user == nil {
+		// Compare against a dummy hash so unknown emails take as long as wrong passwords
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return "", nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Opportunistically prune old sessions
+	if err := repository.DeleteExpiredAdminSessions(); err != nil {
+		log.Printf("Failed to delete expired admin sessions: %v", err)
+	}
+
+	if err := repository.CreateAdminSession(hashToken(token), user.ID, time.Now().Add(SessionTTL)); err != nil {
+		return "", nil, err
+	}
+
+	return token, user, nil
+}
+
+// Authenticate returns the admin user owning a session token, or nil if the token is invalid or expired
+func Authenticate(token string) (*models.AdminUser, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return repository.GetAdminUserBySession(hashToken(token))
+}
+
+// Logout invalidates a session token
+func Logout(token string) error {
+	if token == "" {
+		return nil
+	}
+	return repository.DeleteAdminSession(hashToken(token))
+}
+
+// EnsureBootstrapAdmin creates the first owner account from ADMIN_BOOTSTRAP_EMAIL and
+// ADMIN_BOOTSTRAP_PASSWORD when no admin users exist yet
+func EnsureBootstrapAdmin() error {
+	count, err := repository.CountAdminUsers()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	email := os.Getenv("ADMIN_BOOTSTRAP_EMAIL")
+	password := os.Getenv("ADMIN_BOOTSTRAP_PASSWORD")
+	if email == "" || password == "" {
+		log.Println("No admin users exist; set ADMIN_BOOTSTRAP_EMAIL and ADMIN_BOOTSTRAP_PASSWORD to create the owner account")
+		return nil
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if _, err := repository.CreateAdminUser(email, "Owner", models.RoleOwner, hash); err != nil {
+		return err
+	}
+
+	log.Printf("Created owner account for %s", email)
+	return nil
+}
+
+// generateToken returns a random, URL-safe session token
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken hashes a session token for storage, so a database leak does not expose live sessions
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// ErrEnquiryNotFound is returned when an enquiry does not exist
+var ErrEnquiryNotFound = errors.New("enquiry not found")
+
+// ValidationError is returned when a request breaks a booking or validation rule
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// UnavailableError is returned when some nights of a requested stay are already taken
+type UnavailableError struct {
+	Nights []string
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("dates not available: %s", strings.Join(e.Nights, ", "))
+}
+
+// ValidateStay checks the dates and guest count of a stay against the property and the bedroom config
+// from GetBedroomConfig, which is nil when the property has none
+func ValidateStay(property *models.Property, checkIn, checkOut time.Time, guests int, bedroomConfig *models.BedroomConfig) error {
+	if !checkOut.After(checkIn) {
+		return &ValidationError{"check_out must be after check_in"}
+	}
+
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	if checkIn.Before(today) {
+		return &ValidationError{"check_in cannot be in the past"}
+	}
+
+	if guests < 1 {
+		return &ValidationError{"At least 1 guest is required"}
+	}
+
+	if property.MaxGuests > 0 && guests > property.MaxGuests {
+		return &ValidationError{fmt.Sprintf("%s accommodates at most %d guests", property.Name, property.MaxGuests)}
+	}
+
+	if bedroomConfig != nil && bedroomConfig.MaxGuests > 0 && guests > bedroomConfig.MaxGuests {
+		return &ValidationError{fmt.Sprintf("%s accommodates at most %d guests", bedroomConfig.Name, bedroomConfig.MaxGuests)}
+	}
+
+	return nil
+}
+
+// CreateEnquiry stores a new enquiry after checking, within the same transaction, that none of its
+// nights are blocked or taken by a confirmed enquiry. The quote is stored with it. When the request
+// names a saved quote, that quote's price is used instead, and quote may be nil.
+func CreateEnquiry(req models.CreateEnquiryRequest, quote *models.PriceQuote) (*models.Enquiry, error) {
+	var id string
+	err := database.WithTx(func(tx *sql.Tx) error {
+		if err := repository.LockProperty(tx, req.PropertyID); err != nil {
+			return err
+		}
+
+		nights, err := repository.GetUnavailableNights(tx, req.PropertyID, req.CheckIn, req.CheckOut)
+		if err != nil {
+			return err
+		}
+		if len(nights) > 0 {
+			return &UnavailableError{Nights: nights}
+		}
+
+		if req.QuoteID != "" {
+			if quote, err = bindQuote(tx, req); err != nil {
+				return err
+			}
+		}
+
+		var promo *models.PromoCode
+		if quote.PromoCode != "" {
+			promo, err = repository.GetPromoCodeForUpdate(tx, req.PropertyID, quote.PromoCode)
+			if err != nil {
+				return err
+			}
+			if promo == nil || !promo.Active {
+				return &ValidationError{"Unknown promo code"}
+			}
+			total, byEmail, err := repository.CountPromoRedemptions(tx, promo.ID, req.Email)
+			if err != nil {
+				return err
+			}
+			if err := checkPromoUsage(promo, total, byEmail); err != nil {
+				return err
+			}
+		}
+
+		id, err = repository.CreateEnquiryTx(tx, req, quote)
+		if err != nil {
+			return err
+		}
+
+		if req.QuoteID != "" {
+			if err := repository.UseQuoteTx(tx, req.QuoteID, id); err != nil {
+				return err
+			}
+		}
+
+		if promo != nil {
+			return repository.CreatePromoRedemptionTx(tx, promo.ID, id, req.Email, quote.PromoDiscount)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetEnquiryByID(id)
+}
+
+// UpdateEnquiryStatus changes the status of an enquiry. Confirming blocks its nights on the calendar and
+// fails if any of them is already blocked; moving away from confirmed releases them again.
+func UpdateEnquiryStatus(id, status string) (*models.Enquiry, error) {
+	err := database.WithTx(func(tx *sql.Tx) error {
+		enquiry, err := repository.GetEnquiryForUpdate(tx, id)
+		if err != nil {
+			return err
+		}
+		if enquiry == nil {
+			return ErrEnquiryNotFound
+		}
+
+		wasConfirmed := enquiry.Status == "confirmed"
+		isConfirmed := status == "confirmed"
+
+		switch {
+		case isConfirmed && !wasConfirmed:
+			if err := repository.LockProperty(tx, enquiry.PropertyID); err != nil {
+				return err
+			}
+
+			nights, err := repository.GetUnavailableNights(tx, enquiry.PropertyID, enquiry.CheckIn, enquiry.CheckOut)
+			if err != nil {
+				return err
+			}
+			if len(nights) > 0 {
+				return &UnavailableError{Nights: nights}
+			}
+
+			if err := repository.BlockEnquiryNights(tx, enquiry.ID); err != nil {
+				return err
+			}
+		case wasConfirmed && !isConfirmed:
+			if err := repository.ReleaseEnquiryNights(tx, enquiry.ID); err != nil {
+				return err
+			}
+		}
+
+		return repository.UpdateEnquiryStatusTx(tx, enquiry.ID, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.GetEnquiryByID(id)
+}
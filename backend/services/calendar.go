@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// maxCalendarDays is the longest range of dates one calendar request can cover
+const maxCalendarDays = 366
+
+// BuildCalendar returns the days of a property from first to last, inclusive, with the price of each night
+// for a bedroom config (the default config when none is given), its availability and its stay rules.
+// Everything is loaded in a few queries for the whole range and worked out day by day in memory.
+func BuildCalendar(property *models.Property, first, last time.Time, bedroomConfigID string) (*models.PropertyCalendar, error) {
+	if last.Before(first) {
+		return nil, &ValidationError{"to cannot be before from"}
+	}
+	if int(last.Sub(first).Hours()/24) >= maxCalendarDays {
+		return nil, &ValidationError{fmt.Sprintf("A calendar can cover at most %d days", maxCalendarDays)}
+	}
+
+	bedroomConfig, err := GetBedroomConfig(property.ID, bedroomConfigID)
+	if err != nil {
+		return nil, err
+	}
+
+	pricing, err := repository.GetPricingSeasons(property.ID)
+	if err != nil {
+		return nil, err
+	}
+	stayRules, err := repository.GetStayRulesByPropertyID(property.ID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadRuleContext(property.ID, first, last.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := repository.GetBlockedNights(property.ID, first.Format("2006-01-02"), last.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &models.PropertyCalendar{
+		PropertyID: property.ID,
+		From:       first.Format("2006-01-02"),
+		To:         last.Format("2006-01-02"),
+		Currency:   property.Currency,
+		Days:       []models.CalendarDay{},
+	}
+	if bedroomConfig != nil {
+		calendar.BedroomConfigID = bedroomConfig.ID
+	}
+
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	for _, d := range buildDayRules(pricing.Seasons, stayRules, first, last) {
+		season := seasonForDate(pricing.Seasons, pricing.Overrides, d.Date)
+		night := priceNight(property.ID, d.Date, season, bedroomConfig, property.Currency, rules)
+
+		calendar.Days = append(calendar.Days, models.CalendarDay{
+			Date:              night.Date,
+			Price:             night.TotalPrice,
+			SeasonName:        night.SeasonName,
+			Available:         !blocked[night.Date] && !d.Date.Before(today),
+			MinNights:         d.MinNights,
+			MaxNights:         d.MaxNights,
+			ClosedToArrival:   d.ClosedToArrival != "",
+			ClosedToDeparture: d.ClosedToDeparture != "",
+		})
+	}
+
+	return calendar, nil
+}
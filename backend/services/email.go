@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/smtp"
 	"os"
+	"villa-arama-riverside/models"
 )
 
 // EmailConfig holds SMTP configuration
@@ -15,7 +16,7 @@ type EmailConfig struct {
 }
 
 // SendEnquiryNotification sends an email notification for a new enquiry
-func SendEnquiryNotification(name, email, phone, checkIn, checkOut, message string, guests int, totalPrice float64) error {
+func SendEnquiryNotification(name, email, phone, checkIn, checkOut, message string, guests int, totalPrice models.Money) error {
 	config := EmailConfig{
 		Host:     os.Getenv("SMTP_HOST"),
 		Port:     os.Getenv("SMTP_PORT"),
@@ -47,7 +48,7 @@ Booking Details:
 - Check-in: %s
 - Check-out: %s
 - Guests: %d
-- Estimated Total: $%.2f
+- Estimated Total: %s
 
 Message:
 %s
@@ -1,109 +1,290 @@
 package services
 
 import (
-	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net"
 	"net/http"
+	"os"
 	"strings"
 	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/ical"
+	"villa-arama-riverside/models"
 	"villa-arama-riverside/repository"
 )
 
-// SyncICalFeed fetches and parses an iCal feed, blocking dates
-func SyncICalFeed(icalURLID, propertyID, url, source string) error {
-	// Fetch the iCal feed
-	resp, err := http.Get(url)
+// PropertyLocation returns the time zone of the properties, used to turn timed iCal events into nights.
+// It is configured with PROPERTY_TIMEZONE and defaults to Bali time.
+func PropertyLocation() *time.Location {
+	name := os.Getenv("PROPERTY_TIMEZONE")
+	if name == "" {
+		name = "Asia/Makassar"
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Unknown PROPERTY_TIMEZONE %q, using UTC", name)
+		return time.UTC
+	}
+	return loc
+}
+
+// icalSyncRunsKept is how many sync runs are kept per feed
+const icalSyncRunsKept = 200
+
+// Limits for fetching iCal feeds
+const (
+	icalFetchTimeout = 30 * time.Second
+	icalMaxFeedBytes = 10 << 20
+	icalMaxRedirects = 5
+	icalUserAgent    = "VillaAramaRiverside-CalendarSync/1.0"
+
+	// icalRevalidateAfter forces a full fetch now and then, so the import window keeps moving
+	// and recurring events are expanded into new dates even when the feed never changes
+	icalRevalidateAfter = 24 * time.Hour
+)
+
+// icalHTTPClient is used for every feed fetch, so one slow OTA endpoint cannot pin a worker
+var icalHTTPClient = &http.Client{
+	Timeout: icalFetchTimeout,
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConnsPerHost:   2,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= icalMaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", icalMaxRedirects)
+		}
+		if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+			return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+		}
+		if via[0].URL.Scheme == "https" && req.URL.Scheme != "https" {
+			return errors.New("refusing redirect from https to http")
+		}
+		return nil
+	},
+}
+
+// errFeedTooLarge is returned when a feed is bigger than icalMaxFeedBytes
+var errFeedTooLarge = fmt.Errorf("iCal feed is larger than %d bytes", icalMaxFeedBytes)
+
+// ICalSyncResult summarises one sync of a feed
+type ICalSyncResult struct {
+	HTTPStatus    int
+	NotModified   bool // the feed answered 304, so nothing was parsed or written
+	EventsParsed  int
+	EventsSkipped int
+	DatesAdded    int
+	DatesRemoved  int
+	Warnings      []string // malformed events that were skipped
+}
+
+// SyncICalFeed fetches and parses an iCal feed, blocking dates. Cancelling ctx aborts the fetch.
+// Every run is recorded in the feed's sync history, and a failure is kept as the feed's last error.
+func SyncICalFeed(ctx context.Context, feed models.ICalURL) error {
+	startedAt := time.Now()
+	result, err := syncICalFeed(ctx, feed)
+
+	run := models.ICalSyncRun{
+		ICalURLID:     feed.ID,
+		StartedAt:     startedAt,
+		FinishedAt:    time.Now(),
+		Outcome:       models.ICalSyncSuccess,
+		HTTPStatus:    result.HTTPStatus,
+		EventsParsed:  result.EventsParsed,
+		EventsSkipped: result.EventsSkipped,
+		DatesAdded:    result.DatesAdded,
+		DatesRemoved:  result.DatesRemoved,
+	}
+	if len(result.Warnings) > 0 {
+		run.ErrorMessage = "Skipped malformed events: " + strings.Join(result.Warnings, "; ")
+	}
+
+	status, lastError := "active", ""
+	if err != nil {
+		run.Outcome = models.ICalSyncFailed
+		run.ErrorMessage = describeSyncError(err)
+		status, lastError = "failed", run.ErrorMessage
+	}
+
+	if recordErr := repository.CreateICalSyncRun(run, icalSyncRunsKept); recordErr != nil {
+		log.Printf("Failed to record iCal sync run for %s: %v", feed.ID, recordErr)
+	}
+	if statusErr := repository.UpdateICalURLStatus(feed.ID, status, lastError); statusErr != nil {
+		log.Printf("Failed to update iCal URL status for %s: %v", feed.ID, statusErr)
+	}
+
+	return err
+}
+
+// syncICalFeed does the work of SyncICalFeed, filling in as much of the result as it got to
+func syncICalFeed(ctx context.Context, feed models.ICalURL) (ICalSyncResult, error) {
+	var result ICalSyncResult
+
+	// Fetch the iCal feed, revalidating with the stored ETag/Last-Modified when the last full sync is recent
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return result, fmt.Errorf("invalid iCal feed URL: %w", err)
+	}
+	req.Header.Set("User-Agent", icalUserAgent)
+	req.Header.Set("Accept", "text/calendar, */*;q=0.5")
+	if time.Since(feed.ContentSyncedAt) < icalRevalidateAfter {
+		if feed.ETag != "" {
+			req.Header.Set("If-None-Match", feed.ETag)
+		}
+		if feed.LastModified != "" {
+			req.Header.Set("If-Modified-Since", feed.LastModified)
+		}
+	}
+
+	resp, err := icalHTTPClient.Do(req)
 	if err != nil {
-		repository.UpdateICalURLStatus(icalURLID, "failed")
-		return fmt.Errorf("failed to fetch iCal feed: %w", err)
+		return result, fmt.Errorf("failed to fetch iCal feed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	result.HTTPStatus = resp.StatusCode
+	if resp.StatusCode == http.StatusNotModified {
+		result.NotModified = true
+		return result, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		repository.UpdateICalURLStatus(icalURLID, "failed")
-		return fmt.Errorf("failed to fetch iCal feed: status %d", resp.StatusCode)
-	}
-
-	// Clear existing blocked dates from this source
-	if err := repository.ClearBlockedDatesBySource(propertyID, source); err != nil {
-		return fmt.Errorf("failed to clear existing blocked dates: %w", err)
-	}
-
-	// Parse the iCal feed
-	scanner := bufio.NewScanner(resp.Body)
-	var inEvent bool
-	var eventUID, dtStart, dtEnd string
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		if line == "BEGIN:VEVENT" {
-			inEvent = true
-			eventUID = ""
-			dtStart = ""
-			dtEnd = ""
-		} else if line == "END:VEVENT" {
-			if inEvent && dtStart != "" {
-				// Parse and block dates
-				startDate, err := parseICalDate(dtStart)
-				if err == nil {
-					endDate := startDate
-					if dtEnd != "" {
-						if parsed, err := parseICalDate(dtEnd); err == nil {
-							endDate = parsed
-						}
-					}
-
-					// Block all dates in range
-					current := startDate
-					for !current.After(endDate) {
-						dateStr := current.Format("2006-01-02")
-						repository.AddBlockedDate(propertyID, dateStr, source, eventUID)
-						current = current.AddDate(0, 0, 1)
-					}
-				}
-			}
-			inEvent = false
-		} else if inEvent {
-			if strings.HasPrefix(line, "UID:") {
-				eventUID = strings.TrimPrefix(line, "UID:")
-			} else if strings.HasPrefix(line, "DTSTART") {
-				dtStart = extractDateValue(line)
-			} else if strings.HasPrefix(line, "DTEND") {
-				dtEnd = extractDateValue(line)
+		return result, fmt.Errorf("failed to fetch iCal feed: status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > icalMaxFeedBytes {
+		return result, errFeedTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, icalMaxFeedBytes+1))
+	if err != nil {
+		return result, fmt.Errorf("failed to read iCal feed: %w", err)
+	}
+	if len(body) > icalMaxFeedBytes {
+		return result, errFeedTooLarge
+	}
+
+	// Parse the whole feed before touching the calendar, so a bad response keeps the existing blocks
+	loc := PropertyLocation()
+	cal, err := ical.Parse(bytes.NewReader(body), loc)
+	if err != nil {
+		return result, fmt.Errorf("failed to parse iCal feed: %w", err)
+	}
+
+	result.EventsParsed = len(cal.Events)
+	result.EventsSkipped = len(cal.Errors)
+	for _, parseErr := range cal.Errors {
+		result.Warnings = append(result.Warnings, parseErr.Error())
+	}
+
+	// Work out which nights the feed blocks now; DTEND is exclusive so checkout days stay free
+	from, to := icalWindow()
+	desired := map[string]string{}
+	for _, occurrence := range cal.Occurrences(from, to, loc) {
+		for _, night := range occurrence.Nights(loc) {
+			date := night.Format("2006-01-02")
+			if _, ok := desired[date]; !ok {
+				desired[date] = occurrence.UID
 			}
 		}
 	}
 
-	// Update status to active
-	repository.UpdateICalURLStatus(icalURLID, "active")
+	validators := icalValidators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	result.DatesAdded, result.DatesRemoved, err = applyICalDiff(feed, desired, validators)
+	if err != nil {
+		return result, fmt.Errorf("failed to update blocked dates: %w", err)
+	}
 
-	return nil
+	return result, nil
 }
 
-// parseICalDate parses an iCal date string
-func parseICalDate(dateStr string) (time.Time, error) {
-	// Try different formats
-	formats := []string{
-		"20060102",
-		"20060102T150405",
-		"20060102T150405Z",
+// describeSyncError turns a sync error into a message for the sync history, naming timeouts explicitly
+func describeSyncError(err error) string {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout: " + err.Error()
 	}
+	return err.Error()
+}
+
+// icalValidators are the response headers used for conditional requests on the next sync
+type icalValidators struct {
+	ETag         string
+	LastModified string
+}
 
-	for _, format := range formats {
-		if t, err := time.Parse(format, dateStr); err == nil {
-			return t, nil
+// applyICalDiff brings the dates blocked by a feed in line with desired (date -> event UID) inside one
+// transaction, touching only the rows that changed. Other feeds and direct bookings keep their own rows
+// for the same dates. It returns the number of dates added and removed.
+func applyICalDiff(feed models.ICalURL, desired map[string]string, validators icalValidators) (int, int, error) {
+	icalURLID := feed.ID
+	var added, removed int
+	err := database.WithTx(func(tx *sql.Tx) error {
+		exists, err := repository.LockICalURL(tx, icalURLID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			// The feed was deleted while it was being fetched
+			return nil
+		}
+
+		current, err := repository.GetFeedBlockedDates(tx, icalURLID)
+		if err != nil {
+			return err
+		}
+
+		var addDates, addUIDs, changedDates, changedUIDs, removeDates []string
+		for date, uid := range desired {
+			currentUID, ok := current[date]
+			switch {
+			case !ok:
+				addDates = append(addDates, date)
+				addUIDs = append(addUIDs, uid)
+			case currentUID != uid:
+				changedDates = append(changedDates, date)
+				changedUIDs = append(changedUIDs, uid)
+			}
+		}
+		for date := range current {
+			if _, ok := desired[date]; !ok {
+				removeDates = append(removeDates, date)
+			}
+		}
+
+		if err := repository.RemoveFeedBlockedDates(tx, icalURLID, removeDates); err != nil {
+			return err
+		}
+		if err := repository.UpdateFeedBlockedDateUIDs(tx, icalURLID, changedDates, changedUIDs); err != nil {
+			return err
+		}
+		if err := repository.AddFeedBlockedDates(tx, feed.PropertyID, icalURLID, feed.Source, addDates, addUIDs); err != nil {
+			return err
 		}
-	}
 
-	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+		// Store the validators with the dates they describe, so a 304 never hides an unapplied change
+		if err := repository.SetICalURLValidators(tx, icalURLID, validators.ETag, validators.LastModified); err != nil {
+			return err
+		}
+
+		added, removed = len(addDates), len(removeDates)
+		return nil
+	})
+
+	return added, removed, err
 }
 
-// extractDateValue extracts the date value from an iCal property line
-func extractDateValue(line string) string {
-	// Handle formats like "DTSTART;VALUE=DATE:20231215" or "DTSTART:20231215T120000Z"
-	if idx := strings.LastIndex(line, ":"); idx != -1 {
-		return strings.TrimSpace(line[idx+1:])
-	}
-	return ""
+// icalWindow returns the range of dates imported from and exported to iCal feeds
+func icalWindow() (time.Time, time.Time) {
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	return today.AddDate(0, 0, -icalWindowPastDays), today.AddDate(0, 0, icalWindowFutureDays)
 }
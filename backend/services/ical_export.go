@@ -0,0 +1,148 @@
+package services
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// icalWindowPastDays is how far back imported and exported calendars reach, so recent stays stay visible
+const icalWindowPastDays = 30
+
+// icalWindowFutureDays is how far ahead imported and exported calendars reach
+const icalWindowFutureDays = 2 * 365
+
+// EnsureICalExportTokens gives every property without one a random iCal export token
+func EnsureICalExportTokens() error {
+	ids, err := repository.GetPropertyIDsWithoutICalExportToken()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, err := RotateICalExportToken(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RotateICalExportToken replaces the iCal export token of a property, invalidating the old feed URL
+func RotateICalExportToken(propertyID string) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := repository.SetICalExportToken(propertyID, token); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// CheckICalExportToken reports whether token is the iCal export token of a property
+func CheckICalExportToken(propertyID, token string) (bool, error) {
+	expected, err := repository.GetICalExportToken(propertyID)
+	if err != nil {
+		return false, err
+	}
+	if expected == "" || token == "" {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1, nil
+}
+
+// BuildICalExport renders the unavailable nights of a property as an RFC 5545 calendar,
+// with one all-day VEVENT per contiguous range of blocked nights
+func BuildICalExport(property *models.Property) (string, error) {
+	from, to := icalWindow()
+	nights, err := repository.GetUnavailableNights(database.DB, property.ID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return "", err
+	}
+
+	ranges, err := groupNights(nights)
+	if err != nil {
+		return "", err
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//Villa Arama Riverside//Booking Calendar//EN")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(property.Name))
+
+	for _, r := range ranges {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, fmt.Sprintf("UID:%s-%s@villa-arama-riverside", property.ID, r.start.Format("20060102")))
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+r.start.Format("20060102"))
+		// DTEND is exclusive: the morning after the last blocked night
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+r.end.Format("20060102"))
+		writeICalLine(&b, "SUMMARY:Not available")
+		writeICalLine(&b, "TRANSP:OPAQUE")
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return b.String(), nil
+}
+
+// nightRange is a half-open range of nights [start, end)
+type nightRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// groupNights merges sorted YYYY-MM-DD nights into contiguous ranges
+func groupNights(nights []string) ([]nightRange, error) {
+	var ranges []nightRange
+	for _, n := range nights {
+		night, err := time.Parse("2006-01-02", n)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ranges) > 0 && ranges[len(ranges)-1].end.Equal(night) {
+			ranges[len(ranges)-1].end = night.AddDate(0, 0, 1)
+			continue
+		}
+		ranges = append(ranges, nightRange{start: night, end: night.AddDate(0, 0, 1)})
+	}
+	return ranges, nil
+}
+
+// writeICalLine writes a content line terminated by CRLF, folding it at 75 octets as RFC 5545 requires
+func writeICalLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Do not split a multi-byte UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// escapeICalText escapes a TEXT property value
+func escapeICalText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return r.Replace(s)
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// Retry backoff for failing feeds: the first retry comes after icalRetryBase, doubling up to icalRetryMax
+const (
+	icalRetryBase = time.Minute
+	icalRetryMax  = 6 * time.Hour
+)
+
+// icalJitter spreads syncs by up to ±10% of their delay so feeds added together do not stay in lockstep
+const icalJitter = 0.1
+
+// ICalSchedulerConfig configures the background iCal scheduler
+type ICalSchedulerConfig struct {
+	Workers      int           // maximum number of feeds synced at once
+	PollInterval time.Duration // how often to look for due feeds
+	SyncTimeout  time.Duration // upper bound for a single sync
+}
+
+// ICalScheduler periodically syncs due iCal feeds with a bounded pool of workers
+type ICalScheduler struct {
+	config  ICalSchedulerConfig
+	queue   chan models.ICalURL
+	trigger chan struct{}
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// scheduler is the running scheduler, used by TriggerICalSync
+var scheduler *ICalScheduler
+
+// StartICalScheduler starts the background scheduler. Call Stop on the result during shutdown.
+func StartICalScheduler(config ICalSchedulerConfig) *ICalScheduler {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Minute
+	}
+	if config.SyncTimeout <= 0 {
+		config.SyncTimeout = 2 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &ICalScheduler{
+		config:   config,
+		queue:    make(chan models.ICalURL),
+		trigger:  make(chan struct{}, 1),
+		inFlight: map[string]bool{},
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+
+	s.wg.Add(1)
+	go s.loop()
+
+	scheduler = s
+	log.Printf("iCal scheduler started with %d workers", config.Workers)
+	return s
+}
+
+// Stop stops polling, aborts running syncs and waits for the workers to exit
+func (s *ICalScheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+	log.Println("iCal scheduler stopped")
+}
+
+// TriggerICalSync makes every feed due and wakes the scheduler, returning the number of feeds queued
+func TriggerICalSync() (int, error) {
+	count, err := repository.MarkICalURLsDue()
+	if err != nil {
+		return 0, err
+	}
+	WakeICalScheduler()
+	return count, nil
+}
+
+// WakeICalScheduler makes the scheduler look for due feeds now instead of at its next poll
+func WakeICalScheduler() {
+	if scheduler == nil {
+		return
+	}
+	select {
+	case scheduler.trigger <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// loop polls for due feeds and hands them to the workers
+func (s *ICalScheduler) loop() {
+	defer s.wg.Done()
+	defer close(s.queue)
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatchDue()
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.trigger:
+		}
+	}
+}
+
+// dispatchDue queues every due feed that is not already being synced, blocking while all workers are busy
+func (s *ICalScheduler) dispatchDue() {
+	feeds, err := repository.GetDueICalURLs(time.Now(), 100)
+	if err != nil {
+		log.Printf("iCal scheduler: failed to fetch due feeds: %v", err)
+		return
+	}
+
+	for _, feed := range feeds {
+		s.mu.Lock()
+		busy := s.inFlight[feed.ID]
+		if !busy {
+			s.inFlight[feed.ID] = true
+		}
+		s.mu.Unlock()
+		if busy {
+			continue
+		}
+
+		select {
+		case s.queue <- feed:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// worker syncs feeds from the queue until it is closed
+func (s *ICalScheduler) worker() {
+	defer s.wg.Done()
+
+	for feed := range s.queue {
+		s.sync(feed)
+
+		s.mu.Lock()
+		delete(s.inFlight, feed.ID)
+		s.mu.Unlock()
+	}
+}
+
+// sync runs one feed and schedules its next run: after its interval on success, or with
+// exponential backoff after a failure
+func (s *ICalScheduler) sync(feed models.ICalURL) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.config.SyncTimeout)
+	defer cancel()
+
+	err := SyncICalFeed(ctx, feed)
+
+	// Leave feeds interrupted by shutdown due, so they run first after a restart
+	if errors.Is(err, context.Canceled) && s.ctx.Err() != nil {
+		return
+	}
+
+	failures := 0
+	delay := time.Duration(feed.SyncIntervalMinutes) * time.Minute
+	if delay <= 0 {
+		delay = models.DefaultICalSyncIntervalMinutes * time.Minute
+	}
+
+	if err != nil {
+		failures = feed.ConsecutiveFailures + 1
+		delay = retryDelay(failures)
+		log.Printf("iCal sync of %s failed (attempt %d, retrying in %s): %v", feed.ID, failures, delay.Round(time.Second), err)
+	}
+
+	if err := repository.ScheduleICalURL(feed.ID, time.Now().Add(withJitter(delay)), failures); err != nil {
+		log.Printf("iCal scheduler: failed to schedule %s: %v", feed.ID, err)
+	}
+}
+
+// retryDelay returns the backoff before the given retry of a failing feed
+func retryDelay(failures int) time.Duration {
+	delay := icalRetryBase
+	for i := 1; i < failures && delay < icalRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > icalRetryMax {
+		delay = icalRetryMax
+	}
+	return delay
+}
+
+// withJitter randomly shifts d by up to ±icalJitter of its length
+func withJitter(d time.Duration) time.Duration {
+	spread := float64(d) * icalJitter
+	return d + time.Duration((rand.Float64()*2-1)*spread)
+}
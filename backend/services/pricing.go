@@ -1,110 +1,223 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 	"villa-arama-riverside/models"
 	"villa-arama-riverside/repository"
 )
 
-// CalculatePricing calculates the total price for a date range
-func CalculatePricing(propertyID string, checkIn, checkOut time.Time, bedroomConfigID string) (float64, []models.PropertyPricing, error) {
-	var totalPrice float64
-	var pricingBreakdown []models.PropertyPricing
+// ErrBedroomConfigNotFound is returned when a bedroom config does not exist or belongs to another property
+var ErrBedroomConfigNotFound = errors.New("bedroom config not found for this property")
 
-	// Get bedroom config
-	var bedroomConfig *models.BedroomConfig
-	var err error
-	if bedroomConfigID != "" {
-		bedroomConfig, err = repository.GetBedroomConfigByID(bedroomConfigID)
-	} else {
-		bedroomConfig, err = repository.GetDefaultBedroomConfig()
+// defaultDailyPrice is the nightly price, in the property's currency, of dates no season covers
+const defaultDailyPrice models.Decimal = "200"
+
+// CalculatePricing quotes a stay in the property's currency: its nights priced one by one, the
+// length-of-stay discount, an optional promo code, and the property's fees and taxes for the number
+// of guests. Stays that break a stay rule and promo codes that cannot be used are rejected.
+// Seasons and date price overrides are loaded once, from the season cache, and resolved in memory.
+// The bedroom config comes from GetBedroomConfig, so that callers validating the stay load it once.
+func CalculatePricing(property *models.Property, checkIn, checkOut time.Time, bedroomConfig *models.BedroomConfig, guests int, promoCode string) (*models.PriceQuote, error) {
+	if err := CheckStayRules(property.ID, checkIn, checkOut); err != nil {
+		return nil, err
 	}
+
+	pricing, err := repository.GetPricingSeasons(property.ID)
 	if err != nil {
-		return 0, nil, err
+		return nil, err
 	}
 
-	bedroomPriceAdd := float64(0)
-	bedroomName := ""
-	if bedroomConfig != nil {
-		bedroomPriceAdd = bedroomConfig.PriceAdd
-		bedroomName = bedroomConfig.Name
+	zero := models.Zero(property.Currency)
+	quote := &models.PriceQuote{
+		Currency:      property.Currency,
+		Guests:        guests,
+		Subtotal:      zero,
+		Discount:      zero,
+		PromoDiscount: zero,
+		Fees:          zero,
+		Taxes:         zero,
+		Breakdown:     []models.PropertyPricing{},
 	}
 
-	// Calculate price for each night
-	current := checkIn
-	for current.Before(checkOut) {
-		season, err := repository.GetSeasonForDate(current)
+	rules, err := loadRuleContext(property.ID, checkIn, checkOut)
+	if err != nil {
+		return nil, err
+	}
+
+	priceNights(quote, property.ID, checkIn, checkOut, pricing, bedroomConfig, rules)
+
+	discounts, err := repository.GetStayDiscountsByPropertyID(property.ID)
+	if err != nil {
+		return nil, err
+	}
+	applyStayDiscount(quote, discounts)
+
+	if promoCode = strings.TrimSpace(promoCode); promoCode != "" {
+		promo, err := repository.GetPromoCodeByCode(property.ID, promoCode)
 		if err != nil {
-			return 0, nil, err
+			return nil, err
 		}
-
-		dailyPrice := float64(200) // Default price
-		seasonName := "Regular Season"
-		if season != nil {
-			dailyPrice = season.DailyPrice
-			seasonName = season.Name
+		if err := checkPromoCode(promo, checkIn, checkOut); err != nil {
+			return nil, err
 		}
+		applyPromoCode(quote, promo)
+	}
+
+	fees, err := repository.GetPropertyFeesByPropertyID(property.ID)
+	if err != nil {
+		return nil, err
+	}
+	applyFeesAndTaxes(quote, fees)
 
-		nightTotal := dailyPrice + bedroomPriceAdd
-		totalPrice += nightTotal
+	quote.TotalPrice = quote.Subtotal.Sub(quote.Discount).Sub(quote.PromoDiscount).Add(quote.Fees).Add(quote.Taxes)
+	return quote, nil
+}
 
-		pricingBreakdown = append(pricingBreakdown, models.PropertyPricing{
-			PropertyID:       propertyID,
-			Date:             current.Format("2006-01-02"),
-			SeasonName:       seasonName,
-			SeasonDailyPrice: dailyPrice,
-			BedroomConfigID:  bedroomConfigID,
-			BedroomName:      bedroomName,
-			BedroomPriceAdd:  bedroomPriceAdd,
-			TotalPrice:       nightTotal,
-		})
+// priceNights prices each night of a stay in memory, adding them to the quote's breakdown and subtotal
+// and its accommodation line
+func priceNights(quote *models.PriceQuote, propertyID string, checkIn, checkOut time.Time, pricing *repository.PricingSeasons, bedroomConfig *models.BedroomConfig, rules *ruleContext) {
+	for current := checkIn; current.Before(checkOut); current = current.AddDate(0, 0, 1) {
+		season := seasonForDate(pricing.Seasons, pricing.Overrides, current)
+		night := priceNight(propertyID, current, season, bedroomConfig, quote.Currency, rules)
 
-		current = current.AddDate(0, 0, 1)
+		quote.Subtotal = quote.Subtotal.Add(night.TotalPrice)
+		quote.Breakdown = append(quote.Breakdown, *night)
 	}
-
-	return totalPrice, pricingBreakdown, nil
+	quote.Nights = len(quote.Breakdown)
+	quote.Lines = []models.QuoteLine{{
+		Type:     models.QuoteLineAccommodation,
+		Name:     fmt.Sprintf("%d nights", quote.Nights),
+		Quantity: quote.Nights,
+		Amount:   quote.Subtotal,
+	}}
 }
 
 // GetPricingForDate returns the pricing for a specific date
-func GetPricingForDate(propertyID string, date time.Time, bedroomConfigID string) (*models.PropertyPricing, error) {
-	season, err := repository.GetSeasonForDate(date)
+func GetPricingForDate(property *models.Property, date time.Time, bedroomConfigID string) (*models.PropertyPricing, error) {
+	bedroomConfig, err := GetBedroomConfig(property.ID, bedroomConfigID)
+	if err != nil {
+		return nil, err
+	}
+
+	pricing, err := repository.GetPricingSeasons(property.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := loadRuleContext(property.ID, date, date.AddDate(0, 0, 1))
 	if err != nil {
 		return nil, err
 	}
 
-	dailyPrice := float64(200)
-	seasonName := "Regular Season"
+	season := seasonForDate(pricing.Seasons, pricing.Overrides, date)
+	return priceNight(property.ID, date, season, bedroomConfig, property.Currency, rules), nil
+}
+
+// priceNight prices the night starting on date: the daily price of the season that wins it, its adjustment
+// for the night's weekday, the adjustments of pricing rules, and the bedroom add-on. A nil season means
+// no season covers the date.
+func priceNight(propertyID string, date time.Time, season *models.Season, bedroomConfig *models.BedroomConfig, currency string, rules *ruleContext) *models.PropertyPricing {
+	zero := models.Zero(currency)
+	night := &models.PropertyPricing{
+		PropertyID:        propertyID,
+		Date:              date.Format("2006-01-02"),
+		Currency:          currency,
+		SeasonName:        "Regular Season",
+		SeasonDailyPrice:  models.MoneyFromDecimal(defaultDailyPrice, currency),
+		BedroomPriceAdd:   zero,
+		WeekdayAdjustment: zero,
+	}
 	if season != nil {
-		dailyPrice = season.DailyPrice
-		seasonName = season.Name
+		night.SeasonName = season.Name
+		night.SeasonDailyPrice = models.MoneyFromDecimal(season.DailyPrice, currency)
+		night.WeekdayAdjustment, night.WeekdayAdjustmentName = weekdayAdjustment(season, date, night.SeasonDailyPrice)
+	}
+	rules.apply(night, date)
+	if bedroomConfig != nil {
+		night.BedroomConfigID = bedroomConfig.ID
+		night.BedroomName = bedroomConfig.Name
+		night.BedroomPriceAdd = models.MoneyFromDecimal(bedroomConfig.PriceAdd, currency)
+	}
+
+	night.TotalPrice = night.SeasonDailyPrice.Add(night.WeekdayAdjustment).Add(night.BedroomPriceAdd)
+	for _, a := range night.RuleAdjustments {
+		night.TotalPrice = night.TotalPrice.Add(a.Amount)
+	}
+	return night
+}
+
+// weekdayAdjustment returns the change a season makes to its daily price for the weekday of a night,
+// and a description of it such as "Saturday +20%"
+func weekdayAdjustment(season *models.Season, date time.Time, dailyPrice models.Money) (models.Money, string) {
+	for _, a := range season.WeekdayAdjustments {
+		if a.Weekday != int(date.Weekday()) {
+			continue
+		}
+
+		switch a.Type {
+		case models.WeekdayAdjustmentPrice:
+			price := models.MoneyFromDecimal(a.Amount, dailyPrice.Currency)
+			return price.Sub(dailyPrice), fmt.Sprintf("%s rate", date.Weekday())
+		case models.WeekdayAdjustmentPercent:
+			sign := "+"
+			if a.Amount.Sign() < 0 {
+				sign = ""
+			}
+			return dailyPrice.Percent(a.Amount), fmt.Sprintf("%s %s%s", date.Weekday(), sign, formatPercent(a.Amount))
+		}
+	}
+	return models.Zero(dailyPrice.Currency), ""
+}
+
+// formatPercent formats a percentage without trailing zeros, such as "5.5%"
+func formatPercent(rate models.Decimal) string {
+	s := rate.String()
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
 	}
+	return s + "%"
+}
 
-	var bedroomConfig *models.BedroomConfig
-	if bedroomConfigID != "" {
-		bedroomConfig, err = repository.GetBedroomConfigByID(bedroomConfigID)
-	} else {
-		bedroomConfig, err = repository.GetDefaultBedroomConfig()
+// ValidatePrice rejects a price with more decimals than the property's currency has minor unit digits
+func ValidatePrice(propertyID string, price models.Decimal) error {
+	currency, err := propertyCurrency(propertyID)
+	if err != nil {
+		return err
+	}
+	if !models.CheckPrecision(price, currency) {
+		return &ValidationError{fmt.Sprintf("Prices in %s cannot have more decimals", currency)}
 	}
+	return nil
+}
+
+// propertyCurrency returns the currency a property prices in
+func propertyCurrency(propertyID string) (string, error) {
+	property, err := repository.GetPropertyByID(propertyID)
+	if err != nil {
+		return "", err
+	}
+	if property == nil {
+		return "", ErrPropertyNotFound
+	}
+	return property.Currency, nil
+}
+
+// GetBedroomConfig returns the requested bedroom config of a property, or its default config if none is requested
+func GetBedroomConfig(propertyID, bedroomConfigID string) (*models.BedroomConfig, error) {
+	if bedroomConfigID == "" {
+		return repository.GetDefaultBedroomConfig(propertyID)
+	}
+
+	bedroomConfig, err := repository.GetBedroomConfigByID(bedroomConfigID)
 	if err != nil {
 		return nil, err
 	}
+	if bedroomConfig == nil || bedroomConfig.PropertyID != propertyID {
+		return nil, ErrBedroomConfigNotFound
+	}
 
-	bedroomPriceAdd := float64(0)
-	bedroomName := ""
-	if bedroomConfig != nil {
-		bedroomPriceAdd = bedroomConfig.PriceAdd
-		bedroomName = bedroomConfig.Name
-		bedroomConfigID = bedroomConfig.ID
-	}
-
-	return &models.PropertyPricing{
-		PropertyID:       propertyID,
-		Date:             date.Format("2006-01-02"),
-		SeasonName:       seasonName,
-		SeasonDailyPrice: dailyPrice,
-		BedroomConfigID:  bedroomConfigID,
-		BedroomName:      bedroomName,
-		BedroomPriceAdd:  bedroomPriceAdd,
-		TotalPrice:       dailyPrice + bedroomPriceAdd,
-	}, nil
+	return bedroomConfig, nil
 }
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// ValidatePricingRule checks the type, conditions and percentage of a pricing rule; percentages have at most 2 decimals
+func ValidatePricingRule(rule models.PricingRule) error {
+	switch rule.Type {
+	case models.PricingRuleLeadTime:
+		if rule.MinDaysOut == nil && rule.MaxDaysOut == nil {
+			return &ValidationError{"A lead_time rule needs min_days_out, max_days_out or both"}
+		}
+		if msg := validateIntRange("min_days_out", rule.MinDaysOut, "max_days_out", rule.MaxDaysOut, 0, 3650); msg != "" {
+			return &ValidationError{msg}
+		}
+	case models.PricingRuleOccupancy:
+		if rule.MinOccupancy == nil && rule.MaxOccupancy == nil {
+			return &ValidationError{"An occupancy rule needs min_occupancy, max_occupancy or both"}
+		}
+		if msg := validateIntRange("min_occupancy", rule.MinOccupancy, "max_occupancy", rule.MaxOccupancy, 0, 100); msg != "" {
+			return &ValidationError{msg}
+		}
+		if rule.WindowDays < 1 || rule.WindowDays > 365 {
+			return &ValidationError{"window_days must be between 1 and 365"}
+		}
+	case models.PricingRuleGap:
+		if rule.MaxGapNights < 1 || rule.MaxGapNights > 30 {
+			return &ValidationError{"max_gap_nights must be between 1 and 30"}
+		}
+	default:
+		return &ValidationError{"Invalid type. Must be lead_time, occupancy, or gap"}
+	}
+
+	if rule.Percent.Sign() == 0 || rule.Percent.Cmp(-100) <= 0 || rule.Percent.Cmp(1000) > 0 {
+		return &ValidationError{"percent must not be 0, and must be above -100 and at most 1000"}
+	}
+	if rule.Percent.Places() > 2 {
+		return &ValidationError{"percent can have at most 2 decimals"}
+	}
+	return nil
+}
+
+// ruleContext holds what pricing rules look at when pricing the nights of a stay
+type ruleContext struct {
+	rules   []models.PricingRule // active rules, in the order they were created
+	blocked map[string]bool      // blocked nights around the stay
+	today   time.Time
+}
+
+// loadRuleContext loads a property's active pricing rules and the blocked nights they look at
+// for nights in [from, to)
+func loadRuleContext(propertyID string, from, to time.Time) (*ruleContext, error) {
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	rc := &ruleContext{blocked: map[string]bool{}, today: today}
+
+	rules, err := repository.GetPricingRulesByPropertyID(propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Occupancy windows reach past the stay, and gaps run up to their length on either side
+	before, after := 0, 0
+	for _, r := range rules {
+		if !r.Active {
+			continue
+		}
+		rc.rules = append(rc.rules, r)
+
+		switch r.Type {
+		case models.PricingRuleOccupancy:
+			after = max(after, r.WindowDays)
+		case models.PricingRuleGap:
+			before = max(before, r.MaxGapNights+1)
+			after = max(after, r.MaxGapNights+1)
+		}
+	}
+	if before == 0 && after == 0 {
+		return rc, nil
+	}
+
+	rc.blocked, err = repository.GetBlockedNights(propertyID,
+		from.AddDate(0, 0, -before).Format("2006-01-02"), to.AddDate(0, 0, after).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// apply adds the adjustments of every rule matching a night, each a percentage of the season's
+// price for the night. Adjustments never take that price below zero.
+func (rc *ruleContext) apply(night *models.PropertyPricing, date time.Time) {
+	base := night.SeasonDailyPrice.Add(night.WeekdayAdjustment)
+	price := base
+
+	for _, r := range rc.rules {
+		if !rc.matches(r, date) {
+			continue
+		}
+
+		amount := base.Percent(r.Percent)
+		if price.Add(amount).Minor < 0 {
+			amount = price.Neg()
+		}
+		price = price.Add(amount)
+
+		night.RuleAdjustments = append(night.RuleAdjustments, models.RuleAdjustment{
+			RuleID:  r.ID,
+			Name:    r.Name,
+			Percent: r.Percent,
+			Amount:  amount,
+		})
+	}
+}
+
+// matches reports whether a rule applies to the night starting on date
+func (rc *ruleContext) matches(r models.PricingRule, date time.Time) bool {
+	switch r.Type {
+	case models.PricingRuleLeadTime:
+		daysOut := int(date.Sub(rc.today).Hours() / 24)
+		return inIntRange(daysOut, r.MinDaysOut, r.MaxDaysOut)
+	case models.PricingRuleOccupancy:
+		return inIntRange(rc.occupancy(date, r.WindowDays), r.MinOccupancy, r.MaxOccupancy)
+	case models.PricingRuleGap:
+		return rc.inGap(date, r.MaxGapNights)
+	}
+	return false
+}
+
+// occupancy returns the percentage of the window nights starting on date that are blocked
+func (rc *ruleContext) occupancy(date time.Time, window int) int {
+	if window < 1 {
+		window = models.DefaultOccupancyWindowDays
+	}
+
+	blocked := 0
+	for i := 0; i < window; i++ {
+		if rc.blocked[date.AddDate(0, 0, i).Format("2006-01-02")] {
+			blocked++
+		}
+	}
+	return blocked * 100 / window
+}
+
+// inGap reports whether the night starting on date is free and part of a run of at most maxGap
+// free nights with blocked nights on both sides
+func (rc *ruleContext) inGap(date time.Time, maxGap int) bool {
+	if rc.blocked[date.Format("2006-01-02")] {
+		return false
+	}
+
+	run := 1
+	for _, step := range []int{-1, 1} {
+		for d := date.AddDate(0, 0, step); !rc.blocked[d.Format("2006-01-02")]; d = d.AddDate(0, 0, step) {
+			if run++; run > maxGap {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// inIntRange reports whether n lies within optional inclusive bounds
+func inIntRange(n int, lower, upper *int) bool {
+	return (lower == nil || n >= *lower) && (upper == nil || n <= *upper)
+}
+
+// validateIntRange checks that optional inclusive bounds lie within [lo, hi] and are in order,
+// returning an error message or ""
+func validateIntRange(lowerName string, lower *int, upperName string, upper *int, lo, hi int) string {
+	for _, v := range []*int{lower, upper} {
+		if v != nil && (*v < lo || *v > hi) {
+			return fmt.Sprintf("%s and %s must be between %d and %d", lowerName, upperName, lo, hi)
+		}
+	}
+	if lower != nil && upper != nil && *upper < *lower {
+		return fmt.Sprintf("%s cannot be below %s", upperName, lowerName)
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"testing"
+	"time"
+	"villa-arama-riverside/models"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func intPtr(n int) *int { return &n }
+
+// blockedNights returns a ruleContext on 2025-06-01 with the given nights blocked
+func blockedNights(nights ...string) *ruleContext {
+	rc := &ruleContext{blocked: map[string]bool{}, today: date("2025-06-01")}
+	for _, n := range nights {
+		rc.blocked[n] = true
+	}
+	return rc
+}
+
+func TestRuleMatchesLeadTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max *int
+		night    string
+		want     bool
+	}{
+		{"tonight within last minute", nil, intPtr(7), "2025-06-01", true},
+		{"on the upper bound", nil, intPtr(7), "2025-06-08", true},
+		{"past the upper bound", nil, intPtr(7), "2025-06-09", false},
+		{"on the lower bound", intPtr(60), nil, "2025-07-31", true},
+		{"before the lower bound", intPtr(60), nil, "2025-07-30", false},
+		{"within both bounds", intPtr(10), intPtr(20), "2025-06-15", true},
+		{"below both bounds", intPtr(10), intPtr(20), "2025-06-05", false},
+	}
+
+	rc := blockedNights()
+	for _, tt := range tests {
+		r := models.PricingRule{Type: models.PricingRuleLeadTime, MinDaysOut: tt.min, MaxDaysOut: tt.max}
+		if got := rc.matches(r, date(tt.night)); got != tt.want {
+			t.Errorf("%s: matches(%s) = %t, want %t", tt.name, tt.night, got, tt.want)
+		}
+	}
+}
+
+func TestRuleOccupancy(t *testing.T) {
+	rc := blockedNights("2025-06-10", "2025-06-11", "2025-06-12", "2025-06-20")
+
+	tests := []struct {
+		night  string
+		window int
+		want   int
+	}{
+		{"2025-06-10", 4, 75},  // 3 of 4
+		{"2025-06-10", 3, 100}, // the whole window
+		{"2025-06-13", 7, 0},
+		{"2025-06-10", 11, 36}, // 4 of 11, rounded down
+		{"2025-06-10", 0, 4 * 100 / models.DefaultOccupancyWindowDays}, // the default window
+	}
+
+	for _, tt := range tests {
+		if got := rc.occupancy(date(tt.night), tt.window); got != tt.want {
+			t.Errorf("occupancy(%s, %d) = %d, want %d", tt.night, tt.window, got, tt.want)
+		}
+	}
+
+	r := models.PricingRule{Type: models.PricingRuleOccupancy, MinOccupancy: intPtr(50), MaxOccupancy: intPtr(75), WindowDays: 4}
+	for night, want := range map[string]bool{"2025-06-09": true, "2025-06-10": true, "2025-06-08": true, "2025-06-11": true, "2025-06-12": false, "2025-06-13": false} {
+		if got := rc.matches(r, date(night)); got != want {
+			t.Errorf("occupancy 50-75%% over 4 nights: matches(%s) = %t, want %t", night, got, want)
+		}
+	}
+}
+
+func TestRuleInGap(t *testing.T) {
+	// 06-10 and 06-14 are blocked, leaving a three night gap; 06-16 leaves a one night gap after 06-14
+	rc := blockedNights("2025-06-10", "2025-06-14", "2025-06-16")
+
+	tests := []struct {
+		night  string
+		maxGap int
+		want   bool
+	}{
+		{"2025-06-11", 3, true},
+		{"2025-06-12", 3, true},
+		{"2025-06-13", 3, true},
+		{"2025-06-12", 2, false}, // the gap is longer than the rule
+		{"2025-06-15", 1, true},
+		{"2025-06-15", 3, true},
+		{"2025-06-14", 3, false}, // blocked nights are not gaps
+		// Nights outside the loaded blocked nights count as free, so the free run before 06-10 is open
+		// ended and never a gap, however far it runs
+		{"2025-06-09", 30, false},
+		{"2025-06-17", 30, false},
+	}
+
+	for _, tt := range tests {
+		if got := rc.inGap(date(tt.night), tt.maxGap); got != tt.want {
+			t.Errorf("inGap(%s, %d) = %t, want %t", tt.night, tt.maxGap, got, tt.want)
+		}
+	}
+}
+
+func TestRuleContextApply(t *testing.T) {
+	early := models.PricingRule{ID: "early", Name: "Early bird", Type: models.PricingRuleLeadTime, MinDaysOut: intPtr(30)}
+	lastMinute := models.PricingRule{ID: "late", Name: "Last minute", Type: models.PricingRuleLeadTime, MaxDaysOut: intPtr(7)}
+	gap := models.PricingRule{ID: "gap", Name: "Gap", Type: models.PricingRuleGap, MaxGapNights: 2}
+
+	withPercent := func(r models.PricingRule, percent models.Decimal) models.PricingRule {
+		r.Percent = percent
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		rules   []models.PricingRule
+		night   string
+		weekday models.Decimal // weekday adjustment added to the 100.00 season price
+		want    []models.Decimal
+	}{
+		{"no matching rule", []models.PricingRule{withPercent(early, "-10")}, "2025-06-05", "0", nil},
+		{"one rule", []models.PricingRule{withPercent(lastMinute, "-10")}, "2025-06-05", "0", []models.Decimal{"-10.00"}},
+		{"rules add up on the same base", []models.PricingRule{withPercent(lastMinute, "10"), withPercent(gap, "10")},
+			"2025-06-05", "0", []models.Decimal{"10.00", "10.00"}},
+		{"percent of the weekday price", []models.PricingRule{withPercent(lastMinute, "12.5")}, "2025-06-05", "20", []models.Decimal{"15.00"}},
+		{"rounded to the minor unit", []models.PricingRule{withPercent(lastMinute, "33.33")}, "2025-06-05", "0.01", []models.Decimal{"33.33"}},
+		{"never below zero", []models.PricingRule{withPercent(lastMinute, "-60"), withPercent(gap, "-60")},
+			"2025-06-05", "0", []models.Decimal{"-60.00", "-40.00"}},
+		{"nothing left to take", []models.PricingRule{withPercent(lastMinute, "-99"), withPercent(gap, "-50")},
+			"2025-06-05", "0", []models.Decimal{"-99.00", "-1.00"}},
+	}
+
+	for _, tt := range tests {
+		rc := blockedNights("2025-06-04", "2025-06-07")
+		rc.rules = tt.rules
+
+		night := &models.PropertyPricing{
+			SeasonDailyPrice:  models.MoneyFromDecimal("100", "USD"),
+			WeekdayAdjustment: models.MoneyFromDecimal(tt.weekday, "USD"),
+		}
+		rc.apply(night, date(tt.night))
+
+		if len(night.RuleAdjustments) != len(tt.want) {
+			t.Errorf("%s: %d adjustments, want %d", tt.name, len(night.RuleAdjustments), len(tt.want))
+			continue
+		}
+		for i, a := range night.RuleAdjustments {
+			if a.Amount.Decimal() != tt.want[i] || a.RuleID != tt.rules[i].ID {
+				t.Errorf("%s: adjustment %d = %s by %s, want %s by %s", tt.name, i, a.Amount.Decimal(), a.RuleID, tt.want[i], tt.rules[i].ID)
+			}
+		}
+	}
+}
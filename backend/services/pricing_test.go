@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// testSeasons are recurring seasons, with a new year season wrapping the year end
+func testSeasons() []models.Season {
+	return []models.Season{
+		{ID: "regular", Name: "Regular", StartDate: "01-01", EndDate: "12-31", DailyPrice: "250", IsDefault: true,
+			WeekdayAdjustments: []models.WeekdayAdjustment{}},
+		{ID: "high", Name: "High", StartDate: "07-01", EndDate: "08-31", Priority: 1, DailyPrice: "400",
+			WeekdayAdjustments: []models.WeekdayAdjustment{
+				{Weekday: int(time.Friday), Type: models.WeekdayAdjustmentPercent, Amount: "15"},
+				{Weekday: int(time.Saturday), Type: models.WeekdayAdjustmentPrice, Amount: "480"},
+			}},
+		{ID: "new-year", Name: "New Year", StartDate: "12-20", EndDate: "01-05", Priority: 2, DailyPrice: "550",
+			WeekdayAdjustments: []models.WeekdayAdjustment{}},
+		{ID: "shoulder", Name: "Shoulder", StartDate: "09-01", EndDate: "10-15", Priority: 1, DailyPrice: "320",
+			WeekdayAdjustments: []models.WeekdayAdjustment{}},
+	}
+}
+
+// testOverrides are two night events every five nights from 2025-07-20
+func testOverrides() []models.DatePriceOverride {
+	var overrides []models.DatePriceOverride
+	for i := 0; i < 10; i++ {
+		date := time.Date(2025, 7, 20+i*5, 0, 0, 0, 0, time.UTC)
+		overrides = append(overrides, models.DatePriceOverride{
+			ID:         fmt.Sprintf("override-%d", i),
+			Name:       "Event",
+			StartDate:  date.Format("2006-01-02"),
+			EndDate:    date.AddDate(0, 0, 1).Format("2006-01-02"),
+			DailyPrice: "600",
+			CreatedAt:  date,
+		})
+	}
+	return overrides
+}
+
+// countingDB answers the queries of the repository from testSeasons and testOverrides, with no rows
+// for every other table, and counts them. It stands in for the database when counting round trips.
+type countingDB struct {
+	mu      sync.Mutex
+	queries int
+}
+
+var testDB = &countingDB{}
+
+func init() {
+	sql.Register("counting", testDB)
+}
+
+// useCountingDB points the repository at testDB until the test ends and returns it with its count reset
+func useCountingDB(tb testing.TB) *countingDB {
+	db, err := sql.Open("counting", "")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	previous := database.DB
+	database.DB = db
+	tb.Cleanup(func() {
+		database.DB = previous
+		db.Close()
+	})
+
+	testDB.reset()
+	return testDB
+}
+
+func (d *countingDB) reset() {
+	d.mu.Lock()
+	d.queries = 0
+	d.mu.Unlock()
+}
+
+// count returns the number of queries run since the last reset
+func (d *countingDB) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.queries
+}
+
+func (d *countingDB) Open(string) (driver.Conn, error) {
+	return countingConn{d}, nil
+}
+
+type countingConn struct{ db *countingDB }
+
+func (c countingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("counting: prepared statements are not supported")
+}
+
+func (c countingConn) Close() error { return nil }
+
+func (c countingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("counting: transactions are not supported")
+}
+
+func (c countingConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	c.db.queries++
+	c.db.mu.Unlock()
+
+	epoch := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := &cannedRows{}
+	switch {
+	case strings.Contains(query, "FROM season_weekday_adjustments"):
+		rows.columns = []string{"season_id", "weekday", "adjustment_type", "amount"}
+		for _, s := range testSeasons() {
+			for _, a := range s.WeekdayAdjustments {
+				rows.values = append(rows.values, []driver.Value{s.ID, int64(a.Weekday), a.Type, []byte(a.Amount)})
+			}
+		}
+	case strings.Contains(query, "FROM seasons"):
+		rows.columns = []string{"id", "property_id", "name", "start_date", "end_date", "start_year", "end_year", "priority",
+			"min_nights", "max_nights", "closed_to_arrival", "closed_to_departure", "daily_price", "is_default", "created_at", "updated_at"}
+		for _, s := range testSeasons() {
+			rows.values = append(rows.values, []driver.Value{s.ID, args[0].Value, s.Name, s.StartDate, s.EndDate, nil, nil,
+				int64(s.Priority), int64(0), int64(0), []byte("{}"), []byte("{}"), []byte(s.DailyPrice), s.IsDefault, epoch, epoch})
+		}
+	case strings.Contains(query, "FROM date_price_overrides"):
+		rows.columns = []string{"id", "property_id", "name", "start_date", "end_date", "daily_price", "created_at", "updated_at"}
+		for _, o := range testOverrides() {
+			rows.values = append(rows.values, []driver.Value{o.ID, args[0].Value, o.Name, o.StartDate, o.EndDate,
+				[]byte(o.DailyPrice), o.CreatedAt, o.CreatedAt})
+		}
+	}
+	return rows, nil
+}
+
+// cannedRows are the rows of a query answered by countingDB
+type cannedRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *cannedRows) Columns() []string { return r.columns }
+
+func (r *cannedRows) Close() error { return nil }
+
+func (r *cannedRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// quoteQueries returns the queries CalculatePricing runs for a stay of nights, with the property's
+// seasons already cached or not
+func quoteQueries(tb testing.TB, db *countingDB, nights int, cached bool, propertyID string) int {
+	property := &models.Property{ID: propertyID, Currency: "USD"}
+	checkIn := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+
+	if cached {
+		if _, err := repository.GetPricingSeasons(property.ID); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	db.reset()
+
+	quote, err := CalculatePricing(property, checkIn, checkIn.AddDate(0, 0, nights), nil, 2, "")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if quote.Nights != nights || quote.Breakdown[0].SeasonName != "High" {
+		tb.Fatalf("priced %d nights starting in %q, want %d starting in High", quote.Nights, quote.Breakdown[0].SeasonName, nights)
+	}
+	return db.count()
+}
+
+func TestCalculatePricingQueries(t *testing.T) {
+	db := useCountingDB(t)
+
+	tests := []struct {
+		name   string
+		cached bool
+		want   int
+	}{
+		// seasons, weekday adjustments, overrides, stay rules, pricing rules, stay discounts and fees
+		{"seasons loaded", false, 7},
+		// the season cache leaves stay rules, pricing rules, stay discounts and fees
+		{"seasons cached", true, 4},
+	}
+
+	for _, tt := range tests {
+		for _, nights := range []int{1, 7, 60} {
+			propertyID := fmt.Sprintf("%s-%d", tt.name, nights)
+			if got := quoteQueries(t, db, nights, tt.cached, propertyID); got != tt.want {
+				t.Errorf("%s: quoting %d nights ran %d queries, want %d", tt.name, nights, got, tt.want)
+			}
+		}
+	}
+}
+
+// BenchmarkCalculatePricing quotes stays of 1, 7 and 60 nights against countingDB and reports the
+// queries per quote, which stay the same however long the stay. Pricing each night with its own
+// season lookup, as quotes once did, took two queries a night: 120 more for 60 nights.
+func BenchmarkCalculatePricing(b *testing.B) {
+	db := useCountingDB(b)
+	properties := 0 // a new property for every uncached quote
+
+	for _, cached := range []bool{false, true} {
+		for _, nights := range []int{1, 7, 60} {
+			b.Run(fmt.Sprintf("nights=%d/cached=%t", nights, cached), func(b *testing.B) {
+				queries := 0
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					propertyID := "cached"
+					if !cached {
+						properties++
+						propertyID = fmt.Sprintf("property-%d", properties)
+					}
+					queries += quoteQueries(b, db, nights, cached, propertyID)
+				}
+				b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+			})
+		}
+	}
+}
+
+// BenchmarkPriceNights prices a 60 night stay over recurring seasons, a new year season wrapping
+// the year end and date price overrides, with pricing rules: the work CalculatePricing does in
+// memory for every night once everything is loaded.
+func BenchmarkPriceNights(b *testing.B) {
+	pricing := &repository.PricingSeasons{Seasons: testSeasons(), Overrides: testOverrides()}
+
+	minDays, minOccupancy := 30, 50
+	rules := &ruleContext{
+		rules: []models.PricingRule{
+			{ID: "early", Name: "Early bird", Type: models.PricingRuleLeadTime, MinDaysOut: &minDays, Percent: "-10"},
+			{ID: "busy", Name: "Busy", Type: models.PricingRuleOccupancy, MinOccupancy: &minOccupancy, WindowDays: 14, Percent: "12.5"},
+			{ID: "gap", Name: "Gap", Type: models.PricingRuleGap, MaxGapNights: 3, Percent: "-20"},
+		},
+		blocked: map[string]bool{},
+		today:   time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for _, date := range []string{"2025-07-10", "2025-07-11", "2025-09-20", "2025-09-24", "2025-09-25"} {
+		rules.blocked[date] = true
+	}
+
+	bedroomConfig := &models.BedroomConfig{ID: "three", Name: "3 bedrooms", PriceAdd: "75"}
+	checkIn := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	checkOut := checkIn.AddDate(0, 0, 60)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		quote := &models.PriceQuote{Currency: "USD", Subtotal: models.Zero("USD")}
+		priceNights(quote, "property", checkIn, checkOut, pricing, bedroomConfig, rules)
+		if quote.Nights != 60 {
+			b.Fatalf("priced %d nights, want 60", quote.Nights)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// promoCodePattern matches the codes guests can type: letters, digits, dashes and underscores
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{2,49}$`)
+
+// NormalizePromoCode returns a promo code as it is stored and looked up: trimmed and upper-case
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidatePromoCode checks the code, discount, date windows and limits of a promo code, and that the
+// property has no other promo code with the same code. The ID is empty for a new promo code.
+func ValidatePromoCode(promo models.PromoCode) error {
+	code := NormalizePromoCode(promo.Code)
+	if !promoCodePattern.MatchString(code) {
+		return &ValidationError{"code must be 3 to 50 letters, digits, dashes or underscores"}
+	}
+
+	switch promo.Type {
+	case models.PromoCodePercent:
+		if promo.Amount.Sign() <= 0 || promo.Amount.Cmp(100) > 0 {
+			return &ValidationError{"A percentage discount must be above 0 and at most 100"}
+		}
+		if promo.Amount.Places() > 2 {
+			return &ValidationError{"A percentage can have at most 2 decimals"}
+		}
+	case models.PromoCodeAmount:
+		if promo.Amount.Sign() <= 0 {
+			return &ValidationError{"A discount amount must be above 0"}
+		}
+		if err := ValidatePrice(promo.PropertyID, promo.Amount); err != nil {
+			return err
+		}
+	default:
+		return &ValidationError{"Discount type must be percent or amount"}
+	}
+
+	if msg := validateDateWindow("valid_from", promo.ValidFrom, "valid_until", promo.ValidUntil); msg != "" {
+		return &ValidationError{msg}
+	}
+	if msg := validateDateWindow("stay_from", promo.StayFrom, "stay_until", promo.StayUntil); msg != "" {
+		return &ValidationError{msg}
+	}
+	if promo.MinNights < 0 || promo.MaxUses < 0 || promo.MaxUsesPerEmail < 0 {
+		return &ValidationError{"min_nights, max_uses and max_uses_per_email cannot be negative"}
+	}
+
+	existing, err := repository.GetPromoCodeByCode(promo.PropertyID, code)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != promo.ID {
+		return &ValidationError{fmt.Sprintf("The property already has a promo code %s", existing.Code)}
+	}
+
+	return nil
+}
+
+// checkPromoCode reports why a promo code cannot be used today for a stay, or returns nil.
+// Its per-email limit is checked when the enquiry is made.
+func checkPromoCode(promo *models.PromoCode, checkIn, checkOut time.Time) error {
+	if promo == nil || !promo.Active {
+		return &ValidationError{"Unknown promo code"}
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if promo.ValidFrom != nil && today < *promo.ValidFrom {
+		return &ValidationError{fmt.Sprintf("Promo code %s can be used from %s", promo.Code, *promo.ValidFrom)}
+	}
+	if promo.ValidUntil != nil && today > *promo.ValidUntil {
+		return &ValidationError{fmt.Sprintf("Promo code %s has expired", promo.Code)}
+	}
+
+	firstNight := checkIn.Format("2006-01-02")
+	lastNight := checkOut.AddDate(0, 0, -1).Format("2006-01-02")
+	if (promo.StayFrom != nil && firstNight < *promo.StayFrom) || (promo.StayUntil != nil && lastNight > *promo.StayUntil) {
+		return &ValidationError{fmt.Sprintf("Promo code %s is only valid for stays %s", promo.Code, stayWindow(promo))}
+	}
+
+	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+	if nights < promo.MinNights {
+		return &ValidationError{fmt.Sprintf("Promo code %s requires a stay of at least %d nights", promo.Code, promo.MinNights)}
+	}
+
+	return checkPromoUsage(promo, promo.Redemptions, 0)
+}
+
+// checkPromoUsage reports whether a promo code has reached its limits, given its redemptions
+// in total and by the guest's email address
+func checkPromoUsage(promo *models.PromoCode, total, byEmail int) error {
+	if promo.MaxUses > 0 && total >= promo.MaxUses {
+		return &ValidationError{fmt.Sprintf("Promo code %s has been fully redeemed", promo.Code)}
+	}
+	if promo.MaxUsesPerEmail > 0 && byEmail >= promo.MaxUsesPerEmail {
+		return &ValidationError{fmt.Sprintf("Promo code %s has already been used with this email address", promo.Code)}
+	}
+	return nil
+}
+
+// applyPromoCode takes a promo code's discount off the accommodation left after the stay discount
+func applyPromoCode(quote *models.PriceQuote, promo *models.PromoCode) {
+	base := quote.Subtotal.Sub(quote.Discount)
+
+	discount := models.MoneyFromDecimal(promo.Amount, quote.Currency)
+	if promo.Type == models.PromoCodePercent {
+		discount = base.Percent(promo.Amount)
+	}
+	discount = discount.Min(base)
+
+	quote.PromoCode = promo.Code
+	quote.PromoDiscount = discount
+	quote.Lines = append(quote.Lines, models.QuoteLine{
+		Type:   models.QuoteLineDiscount,
+		Name:   fmt.Sprintf("%s (%s)", promo.Name, promo.Code),
+		Amount: discount.Neg(),
+	})
+}
+
+// stayWindow describes the stay dates of a promo code, such as "from 2025-01-10 until 2025-03-31"
+func stayWindow(promo *models.PromoCode) string {
+	var parts []string
+	if promo.StayFrom != nil {
+		parts = append(parts, "from "+*promo.StayFrom)
+	}
+	if promo.StayUntil != nil {
+		parts = append(parts, "until "+*promo.StayUntil)
+	}
+	return strings.Join(parts, " ")
+}
+
+// validateDateWindow checks an optional YYYY-MM-DD date range where either end may be open,
+// returning an error message or ""
+func validateDateWindow(fromName string, from *string, untilName string, until *string) string {
+	if from != nil {
+		if _, err := time.Parse("2006-01-02", *from); err != nil {
+			return fmt.Sprintf("Invalid %s format, expected YYYY-MM-DD", fromName)
+		}
+	}
+	if until != nil {
+		if _, err := time.Parse("2006-01-02", *until); err != nil {
+			return fmt.Sprintf("Invalid %s format, expected YYYY-MM-DD", untilName)
+		}
+	}
+	if from != nil && until != nil && *until < *from {
+		return fmt.Sprintf("%s cannot be before %s", untilName, fromName)
+	}
+	return ""
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// ErrPropertyNotFound is returned when a property does not exist
+var ErrPropertyNotFound = errors.New("property not found")
+
+// ErrPropertyHasEnquiries is returned when deleting a property that guests have enquired about
+var ErrPropertyHasEnquiries = errors.New("property has enquiries")
+
+// ErrPropertyHasPrices is returned when changing the currency of a property that already has prices
+var ErrPropertyHasPrices = errors.New("property has prices in its currency")
+
+// CreateProperty validates and creates a property, giving it an iCal export token
+func CreateProperty(req models.CreatePropertyRequest) (*models.Property, error) {
+	if req.Status == "" {
+		req.Status = models.PropertyStatusDraft
+	}
+	if req.Currency == "" {
+		req.Currency = models.DefaultCurrency
+	}
+
+	p := models.Property{
+		Name:        strings.TrimSpace(req.Name),
+		Tagline:     strings.TrimSpace(req.Tagline),
+		Description: strings.TrimSpace(req.Description),
+		Location:    strings.TrimSpace(req.Location),
+		ImageURL:    strings.TrimSpace(req.ImageURL),
+		Amenities:   cleanList(req.Amenities),
+		MaxGuests:   req.MaxGuests,
+		Bedrooms:    req.Bedrooms,
+		Bathrooms:   req.Bathrooms,
+		Status:      req.Status,
+		Currency:    strings.ToUpper(strings.TrimSpace(req.Currency)),
+	}
+	if err := validateProperty(p); err != nil {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	property, err := repository.CreateProperty(p, token)
+	if err != nil {
+		return nil, err
+	}
+	property.Images = []models.PropertyImage{}
+	return property, nil
+}
+
+// UpdateProperty applies a partial update to a property. The currency can only change while the
+// property has no prices, as they are not converted.
+func UpdateProperty(id string, req models.UpdatePropertyRequest) (*models.Property, error) {
+	p, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrPropertyNotFound
+	}
+	currency := p.Currency
+
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = strings.TrimSpace(*src)
+		}
+	}
+	setString(&p.Name, req.Name)
+	setString(&p.Tagline, req.Tagline)
+	setString(&p.Description, req.Description)
+	setString(&p.Location, req.Location)
+	setString(&p.ImageURL, req.ImageURL)
+	setString(&p.Status, req.Status)
+	if req.Currency != nil {
+		p.Currency = strings.ToUpper(strings.TrimSpace(*req.Currency))
+	}
+
+	if req.MaxGuests != nil {
+		p.MaxGuests = *req.MaxGuests
+	}
+	if req.Bedrooms != nil {
+		p.Bedrooms = *req.Bedrooms
+	}
+	if req.Bathrooms != nil {
+		p.Bathrooms = *req.Bathrooms
+	}
+
+	p.Amenities = patchList(p.Amenities, req.Amenities, req.AddAmenities, req.RemoveAmenities)
+
+	if err := validateProperty(*p); err != nil {
+		return nil, err
+	}
+
+	if p.Currency != currency {
+		priced, err := repository.PropertyHasPrices(id)
+		if err != nil {
+			return nil, err
+		}
+		if priced {
+			return nil, ErrPropertyHasPrices
+		}
+	}
+
+	p, err = repository.UpdateProperty(*p)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := []models.Property{*p}
+	if err := AttachPropertyImages(properties); err != nil {
+		return nil, err
+	}
+	return &properties[0], nil
+}
+
+// DeleteProperty deletes a property and its calendar data. Properties with enquiries
+// are kept for the booking history and should be archived instead.
+func DeleteProperty(id string) error {
+	p, err := repository.GetPropertyByID(id)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return ErrPropertyNotFound
+	}
+
+	count, err := repository.CountEnquiriesByPropertyID(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrPropertyHasEnquiries
+	}
+
+	images, err := repository.GetPropertyImages([]string{id})
+	if err != nil {
+		return err
+	}
+
+	err = database.WithTx(func(tx *sql.Tx) error {
+		return repository.DeleteProperty(tx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Image rows cascade with the property; their files are removed once that is committed
+	for _, img := range images[id] {
+		deleteImageFiles(img)
+	}
+	return nil
+}
+
+// validateProperty checks the fields of a property before it is saved
+func validateProperty(p models.Property) error {
+	if p.Name == "" {
+		return &ValidationError{"Name is required"}
+	}
+	if len(p.Name) > 255 {
+		return &ValidationError{"Name must be at most 255 characters"}
+	}
+	if len(p.Tagline) > 500 {
+		return &ValidationError{"Tagline must be at most 500 characters"}
+	}
+	if !models.IsValidPropertyStatus(p.Status) {
+		return &ValidationError{"Invalid status. Must be draft, published, or archived"}
+	}
+	if !models.IsValidCurrency(p.Currency) {
+		return &ValidationError{"Invalid currency. Must be USD, IDR, EUR, AUD, SGD, GBP, or JPY"}
+	}
+	if p.MaxGuests < 1 {
+		return &ValidationError{"max_guests must be at least 1"}
+	}
+	if p.Bedrooms < 0 || p.Bathrooms < 0 {
+		return &ValidationError{"bedrooms and bathrooms cannot be negative"}
+	}
+
+	if p.ImageURL != "" && !isWebURL(p.ImageURL) {
+		return &ValidationError{"image_url must be an http or https URL"}
+	}
+
+	if p.Status == models.PropertyStatusPublished && p.Description == "" {
+		return &ValidationError{"A description is required before publishing"}
+	}
+
+	return nil
+}
+
+// patchList replaces list when replace is given, then removes and appends items, keeping the order
+func patchList(list []string, replace *[]string, add, remove []string) []string {
+	if replace != nil {
+		list = *replace
+	}
+
+	removed := map[string]bool{}
+	for _, item := range remove {
+		removed[strings.TrimSpace(item)] = true
+	}
+
+	var result []string
+	for _, item := range list {
+		if !removed[strings.TrimSpace(item)] {
+			result = append(result, item)
+		}
+	}
+
+	return cleanList(append(result, add...))
+}
+
+// cleanList trims items and drops blanks and duplicates, never returning nil
+func cleanList(items []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		result = append(result, item)
+	}
+	return result
+}
+
+// isWebURL reports whether s is an absolute http or https URL
+func isWebURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"villa-arama-riverside/models"
+)
+
+// ValidatePropertyFee checks the type, amount and included guests of a property fee
+func ValidatePropertyFee(fee models.PropertyFee) error {
+	if !models.IsValidFeeType(fee.Type) {
+		return &ValidationError{"Invalid type. Must be per_stay, per_night, per_guest, per_guest_per_night, or percent"}
+	}
+	if fee.Amount.Sign() <= 0 {
+		return &ValidationError{"amount must be above 0"}
+	}
+	if fee.Type == models.FeePercent {
+		if fee.Amount.Cmp(100) > 0 {
+			return &ValidationError{"A percentage tax cannot be above 100"}
+		}
+		if fee.Amount.Places() > 2 {
+			return &ValidationError{"A percentage can have at most 2 decimals"}
+		}
+	} else if err := ValidatePrice(fee.PropertyID, fee.Amount); err != nil {
+		return err
+	}
+	if fee.IncludedGuests < 0 {
+		return &ValidationError{"included_guests cannot be negative"}
+	}
+	return nil
+}
+
+// applyFeesAndTaxes adds the property's fees to a quote, then its percentage taxes on the
+// discounted accommodation and the taxable fees. Every tax is charged on the same amount.
+func applyFeesAndTaxes(quote *models.PriceQuote, fees []models.PropertyFee) {
+	taxable := quote.Subtotal.Sub(quote.Discount).Sub(quote.PromoDiscount)
+
+	for _, f := range fees {
+		var quantity int
+		switch f.Type {
+		case models.FeePerStay:
+			quantity = 1
+		case models.FeePerNight:
+			quantity = quote.Nights
+		case models.FeePerGuest:
+			quantity = chargedGuests(quote.Guests, f.IncludedGuests)
+		case models.FeePerGuestPerNight:
+			quantity = chargedGuests(quote.Guests, f.IncludedGuests) * quote.Nights
+		default:
+			continue
+		}
+		if quantity == 0 {
+			continue
+		}
+
+		unitPrice := models.MoneyFromDecimal(f.Amount, quote.Currency)
+		amount := unitPrice.Mul(quantity)
+		quote.Fees = quote.Fees.Add(amount)
+		if f.Taxable {
+			taxable = taxable.Add(amount)
+		}
+		quote.Lines = append(quote.Lines, models.QuoteLine{
+			Type:      models.QuoteLineFee,
+			Name:      f.Name,
+			Quantity:  quantity,
+			UnitPrice: &unitPrice,
+			Amount:    amount,
+		})
+	}
+
+	for _, f := range fees {
+		if f.Type != models.FeePercent {
+			continue
+		}
+
+		amount := taxable.Percent(f.Amount)
+		quote.Taxes = quote.Taxes.Add(amount)
+		quote.Lines = append(quote.Lines, models.QuoteLine{
+			Type:   models.QuoteLineTax,
+			Name:   fmt.Sprintf("%s (%s)", f.Name, formatPercent(f.Amount)),
+			Rate:   f.Amount,
+			Amount: amount,
+		})
+	}
+}
+
+// chargedGuests returns how many guests a per-guest fee charges
+func chargedGuests(guests, included int) int {
+	if guests <= included {
+		return 0
+	}
+	return guests - included
+}
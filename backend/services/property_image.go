@@ -0,0 +1,298 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // register decoders for uploads
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"villa-arama-riverside/database"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+	"villa-arama-riverside/storage"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxImageUploadBytes is the largest photo that can be uploaded
+const MaxImageUploadBytes = 20 << 20
+
+// maxImagePixels guards against decompression bombs: small files that decode to huge images
+const maxImagePixels = 50_000_000
+
+// imageVariants are the resized copies made of every upload, by maximum width
+var imageVariants = []struct {
+	Name     string
+	MaxWidth int
+}{
+	{models.ImageVariantThumbnail, 320},
+	{models.ImageVariantCard, 800},
+	{models.ImageVariantHero, 1920},
+}
+
+// imageExtensions are the accepted upload types and the extension their original is stored with
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// ErrPropertyImageNotFound is returned when an image does not exist or belongs to another property
+var ErrPropertyImageNotFound = errors.New("property image not found")
+
+// UploadPropertyImage stores an uploaded photo with its resized variants and adds it to the property
+func UploadPropertyImage(propertyID string, data []byte, caption, altText string) (*models.PropertyImage, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, &ValidationError{"Only JPEG, PNG and WebP images can be uploaded"}
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &ValidationError{"The file is not a readable image"}
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, &ValidationError{fmt.Sprintf("Images can be at most %d megapixels", maxImagePixels/1_000_000)}
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &ValidationError{"The file is not a readable image"}
+	}
+
+	id := uuid.New().String()
+	originalKey := path.Join("properties", propertyID, id, "original"+ext)
+
+	var stored []string
+	cleanup := func() {
+		for _, key := range stored {
+			if err := storage.Default.Delete(key); err != nil {
+				log.Printf("Failed to remove %s: %v", key, err)
+			}
+		}
+	}
+
+	if err := storage.Default.Put(originalKey, bytes.NewReader(data), contentType); err != nil {
+		return nil, err
+	}
+	stored = append(stored, originalKey)
+
+	for _, variant := range imageVariants {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resizeImage(src, variant.MaxWidth), &jpeg.Options{Quality: 82}); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		key := variantKey(originalKey, variant.Name)
+		if err := storage.Default.Put(key, &buf, "image/jpeg"); err != nil {
+			cleanup()
+			return nil, err
+		}
+		stored = append(stored, key)
+	}
+
+	img, err := repository.CreatePropertyImage(models.PropertyImage{
+		ID:          id,
+		PropertyID:  propertyID,
+		StorageKey:  originalKey,
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+		SizeBytes:   int64(len(data)),
+		Caption:     strings.TrimSpace(caption),
+		AltText:     strings.TrimSpace(altText),
+	})
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	resolveImageURLs(img)
+	return img, nil
+}
+
+// AddExternalPropertyImage adds a photo hosted elsewhere to a property
+func AddExternalPropertyImage(propertyID, url, caption, altText string) (*models.PropertyImage, error) {
+	url = strings.TrimSpace(url)
+	if !isWebURL(url) {
+		return nil, &ValidationError{"url must be an http or https URL"}
+	}
+
+	img, err := repository.CreatePropertyImage(models.PropertyImage{
+		PropertyID: propertyID,
+		URL:        url,
+		Caption:    strings.TrimSpace(caption),
+		AltText:    strings.TrimSpace(altText),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resolveImageURLs(img)
+	return img, nil
+}
+
+// UpdatePropertyImage changes the caption and alt text of an image
+func UpdatePropertyImage(propertyID, imageID string, req models.UpdatePropertyImageRequest) (*models.PropertyImage, error) {
+	img, err := getPropertyImage(propertyID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	caption, altText := img.Caption, img.AltText
+	if req.Caption != nil {
+		caption = strings.TrimSpace(*req.Caption)
+	}
+	if req.AltText != nil {
+		altText = strings.TrimSpace(*req.AltText)
+	}
+
+	img, err = repository.UpdatePropertyImage(imageID, caption, altText)
+	if err != nil {
+		return nil, err
+	}
+
+	resolveImageURLs(img)
+	return img, nil
+}
+
+// ReorderPropertyImages puts the images of a property in the given order, which must list each of them once
+func ReorderPropertyImages(propertyID string, ids []string) error {
+	return database.WithTx(func(tx *sql.Tx) error {
+		current, err := repository.GetPropertyImageIDs(tx, propertyID)
+		if err != nil {
+			return err
+		}
+
+		wanted := map[string]bool{}
+		for _, id := range ids {
+			wanted[id] = true
+		}
+		if len(wanted) != len(ids) || len(ids) != len(current) {
+			return &ValidationError{"image_ids must list every image of the property exactly once"}
+		}
+		for _, id := range current {
+			if !wanted[id] {
+				return &ValidationError{"image_ids must list every image of the property exactly once"}
+			}
+		}
+
+		return repository.SetPropertyImageOrder(tx, propertyID, ids)
+	})
+}
+
+// DeletePropertyImage removes an image and its stored files
+func DeletePropertyImage(propertyID, imageID string) error {
+	img, err := getPropertyImage(propertyID, imageID)
+	if err != nil {
+		return err
+	}
+
+	if err := repository.DeletePropertyImage(imageID); err != nil {
+		return err
+	}
+
+	deleteImageFiles(*img)
+	return nil
+}
+
+// AttachPropertyImages loads the images of each property into its Images field
+func AttachPropertyImages(properties []models.Property) error {
+	ids := make([]string, len(properties))
+	for i, p := range properties {
+		ids[i] = p.ID
+	}
+
+	images, err := repository.GetPropertyImages(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range properties {
+		properties[i].Images = images[properties[i].ID]
+		if properties[i].Images == nil {
+			properties[i].Images = []models.PropertyImage{}
+		}
+		for j := range properties[i].Images {
+			resolveImageURLs(&properties[i].Images[j])
+		}
+	}
+
+	return nil
+}
+
+// getPropertyImage returns an image, checking that it belongs to the property
+func getPropertyImage(propertyID, imageID string) (*models.PropertyImage, error) {
+	img, err := repository.GetPropertyImageByID(imageID)
+	if err != nil {
+		return nil, err
+	}
+	if img == nil || img.PropertyID != propertyID {
+		return nil, ErrPropertyImageNotFound
+	}
+	return img, nil
+}
+
+// resolveImageURLs fills in the URLs of an uploaded image from the storage backend
+func resolveImageURLs(img *models.PropertyImage) {
+	img.Variants = map[string]string{}
+	if img.StorageKey == "" {
+		return
+	}
+
+	img.URL = storage.Default.URL(img.StorageKey)
+	for _, variant := range imageVariants {
+		img.Variants[variant.Name] = storage.Default.URL(variantKey(img.StorageKey, variant.Name))
+	}
+}
+
+// deleteImageFiles removes the stored files of an uploaded image, logging failures
+func deleteImageFiles(img models.PropertyImage) {
+	if img.StorageKey == "" {
+		return
+	}
+
+	keys := []string{img.StorageKey}
+	for _, variant := range imageVariants {
+		keys = append(keys, variantKey(img.StorageKey, variant.Name))
+	}
+	for _, key := range keys {
+		if err := storage.Default.Delete(key); err != nil {
+			log.Printf("Failed to remove %s: %v", key, err)
+		}
+	}
+}
+
+// variantKey returns the storage key of a variant, next to the original
+func variantKey(originalKey, variant string) string {
+	return path.Join(path.Dir(originalKey), variant+".jpg")
+}
+
+// resizeImage scales src down to maxWidth, keeping the aspect ratio; smaller images are not enlarged
+func resizeImage(src image.Image, maxWidth int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	// JPEG has no transparency, so flatten onto white
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
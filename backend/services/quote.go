@@ -0,0 +1,64 @@
+package services
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+	"time"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// QuoteTTL is how long a saved quote can be used for an enquiry
+const QuoteTTL = 30 * time.Minute
+
+// CreateQuote prices a stay like CalculatePricing and saves the quote, so that an enquiry for the
+// same stay made before it expires is charged the quoted price
+func CreateQuote(property *models.Property, checkIn, checkOut time.Time, bedroomConfigID string, guests int, promoCode string) (*models.Quote, error) {
+	bedroomConfig, err := GetBedroomConfig(property.ID, bedroomConfigID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateStay(property, checkIn, checkOut, guests, bedroomConfig); err != nil {
+		return nil, err
+	}
+
+	quote, err := CalculatePricing(property, checkIn, checkOut, bedroomConfig, guests, promoCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// Opportunistically prune quotes nobody used
+	if err := repository.DeleteExpiredQuotes(); err != nil {
+		log.Printf("Failed to delete expired quotes: %v", err)
+	}
+
+	return repository.CreateQuote(property.ID, checkIn.Format("2006-01-02"), checkOut.Format("2006-01-02"),
+		bedroomConfigID, quote, time.Now().Add(QuoteTTL))
+}
+
+// bindQuote returns the price of the saved quote an enquiry names, locking the quote for the rest of
+// the transaction. The quote must be for the enquiry's stay, unused and not expired.
+func bindQuote(tx *sql.Tx, req models.CreateEnquiryRequest) (*models.PriceQuote, error) {
+	saved, err := repository.GetQuoteForUpdate(tx, req.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return nil, &ValidationError{"Unknown quote"}
+	}
+
+	if saved.PropertyID != req.PropertyID || saved.CheckIn != req.CheckIn || saved.CheckOut != req.CheckOut ||
+		saved.Guests != req.Guests || saved.BedroomConfigID != req.BedroomConfigID ||
+		!strings.EqualFold(strings.TrimSpace(req.PromoCode), saved.PromoCode) {
+		return nil, &ValidationError{"The quote is for a different stay, please request a new quote"}
+	}
+	if saved.EnquiryID != nil {
+		return nil, &ValidationError{"The quote has already been used"}
+	}
+	if !time.Now().Before(saved.ExpiresAt) {
+		return nil, &ValidationError{"The quote has expired, please request a new quote"}
+	}
+
+	return &saved.PriceQuote, nil
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// ErrDefaultSeasonExists is returned when a second default season is set for a property
+var ErrDefaultSeasonExists = errors.New("property already has a default season")
+
+// monthDayPattern matches the MM-DD format of season dates
+var monthDayPattern = regexp.MustCompile(`^\d{2}-\d{2}$`)
+
+// Season sources in the overlap report
+const (
+	seasonSourceOverride = "override"
+	seasonSourceSeason   = "season"
+	seasonSourceDefault  = "default"
+	seasonSourceNone     = "none"
+)
+
+// ValidateSeason checks the dates, years, stay limits, closed weekdays and weekday adjustments of a season, and that a property keeps
+// a single default season. The ID is empty for a new season.
+func ValidateSeason(season models.Season) error {
+	if !isValidMonthDay(season.StartDate) {
+		return &ValidationError{fmt.Sprintf("Invalid start_date %q, expected a real MM-DD date", season.StartDate)}
+	}
+	if !isValidMonthDay(season.EndDate) {
+		return &ValidationError{fmt.Sprintf("Invalid end_date %q, expected a real MM-DD date", season.EndDate)}
+	}
+
+	startYear, endYear := season.StartYear, season.EndYear
+	for _, year := range []*int{startYear, endYear} {
+		if year != nil && (*year < 2000 || *year > 2100) {
+			return &ValidationError{"start_year and end_year must be between 2000 and 2100"}
+		}
+	}
+	if startYear != nil && endYear != nil && *endYear < *startYear {
+		return &ValidationError{"end_year cannot be before start_year"}
+	}
+
+	if msg := validateStayLimits(season.MinNights, season.MaxNights); msg != "" {
+		return &ValidationError{msg}
+	}
+	if season.DailyPrice.Sign() < 0 {
+		return &ValidationError{"daily_price cannot be negative"}
+	}
+	if err := ValidatePrice(season.PropertyID, season.DailyPrice); err != nil {
+		return err
+	}
+	if msg := validateClosedWeekdays(season.ClosedToArrival, season.ClosedToDeparture); msg != "" {
+		return &ValidationError{msg}
+	}
+	if msg := validateWeekdayAdjustments(season.WeekdayAdjustments); msg != "" {
+		return &ValidationError{msg}
+	}
+	for _, a := range season.WeekdayAdjustments {
+		if a.Type != models.WeekdayAdjustmentPrice {
+			continue
+		}
+		if err := ValidatePrice(season.PropertyID, a.Amount); err != nil {
+			return err
+		}
+	}
+
+	if season.IsDefault {
+		current, err := repository.GetDefaultSeason(season.PropertyID)
+		if err != nil {
+			return err
+		}
+		if current != nil && current.ID != season.ID {
+			return ErrDefaultSeasonExists
+		}
+	}
+
+	return nil
+}
+
+// ValidateDatePriceOverride checks the dates and price of a date price override
+func ValidateDatePriceOverride(override models.DatePriceOverride) error {
+	start, err := time.Parse("2006-01-02", override.StartDate)
+	if err != nil {
+		return &ValidationError{"Invalid start_date format, expected YYYY-MM-DD"}
+	}
+	end, err := time.Parse("2006-01-02", override.EndDate)
+	if err != nil {
+		return &ValidationError{"Invalid end_date format, expected YYYY-MM-DD"}
+	}
+	if end.Before(start) {
+		return &ValidationError{"end_date cannot be before start_date"}
+	}
+	if override.DailyPrice.Sign() <= 0 {
+		return &ValidationError{"daily_price must be greater than 0"}
+	}
+	return ValidatePrice(override.PropertyID, override.DailyPrice)
+}
+
+// BuildSeasonOverlapReport lists the seasons of a property that overlap in a year,
+// and which price applies on each day of it
+func BuildSeasonOverlapReport(propertyID string, year int) (*models.SeasonOverlapReport, error) {
+	seasons, err := repository.GetSeasonsByPropertyID(propertyID)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := repository.GetDatePriceOverridesByPropertyID(propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SeasonOverlapReport{
+		PropertyID: propertyID,
+		Year:       year,
+		Overlaps:   []models.SeasonOverlap{},
+	}
+
+	// Open overlap runs, keyed by the pair of season IDs
+	open := map[[2]string]int{}
+
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for date := first; date.Year() == year; date = date.AddDate(0, 0, 1) {
+		day := models.SeasonDay{Date: date.Format("2006-01-02"), Source: seasonSourceNone}
+
+		candidates := matchingSeasons(seasons, date)
+		if len(candidates) > 1 {
+			for _, s := range candidates {
+				day.Candidates = append(day.Candidates, models.SeasonRef{ID: s.ID, Name: s.Name})
+			}
+		}
+
+		if override := matchingOverride(overrides, day.Date); override != nil {
+			day.Source = seasonSourceOverride
+			day.Winner = &models.SeasonRef{ID: override.ID, Name: override.Name}
+			day.DailyPrice = override.DailyPrice
+		} else if len(candidates) > 0 {
+			day.Source = seasonSourceSeason
+			day.Winner = &models.SeasonRef{ID: candidates[0].ID, Name: candidates[0].Name}
+			day.DailyPrice = candidates[0].DailyPrice
+		} else if def := defaultSeason(seasons, date); def != nil {
+			day.Source = seasonSourceDefault
+			day.Winner = &models.SeasonRef{ID: def.ID, Name: def.Name}
+			day.DailyPrice = def.DailyPrice
+		}
+		report.Days = append(report.Days, day)
+
+		// Extend the runs of every pair of seasons that both apply today, and close the others
+		seen := map[[2]string]bool{}
+		for i := 0; i < len(candidates); i++ {
+			for j := i + 1; j < len(candidates); j++ {
+				winner, loser := candidates[i], candidates[j]
+				key := [2]string{winner.ID, loser.ID}
+				if loser.ID < winner.ID {
+					key = [2]string{loser.ID, winner.ID}
+				}
+				seen[key] = true
+
+				if idx, ok := open[key]; ok {
+					report.Overlaps[idx].To = day.Date
+					continue
+				}
+				open[key] = len(report.Overlaps)
+				report.Overlaps = append(report.Overlaps, models.SeasonOverlap{
+					Seasons: [2]models.SeasonRef{{ID: winner.ID, Name: winner.Name}, {ID: loser.ID, Name: loser.Name}},
+					From:    day.Date,
+					To:      day.Date,
+					Winner:  models.SeasonRef{ID: winner.ID, Name: winner.Name},
+				})
+			}
+		}
+		for key := range open {
+			if !seen[key] {
+				delete(open, key)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// matchingSeasons returns the non-default seasons that apply on a date, the winning season first
+func matchingSeasons(seasons []models.Season, date time.Time) []models.Season {
+	var matches []models.Season
+	for _, s := range seasons {
+		if !s.IsDefault && seasonApplies(s, date) {
+			matches = append(matches, s)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if seasonRank(a) != seasonRank(b) {
+			return seasonRank(a) < seasonRank(b)
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+
+	return matches
+}
+
+// defaultSeason returns the default season if it applies on a date
+func defaultSeason(seasons []models.Season, date time.Time) *models.Season {
+	for i := range seasons {
+		if seasons[i].IsDefault && seasonApplies(seasons[i], date) {
+			return &seasons[i]
+		}
+	}
+	return nil
+}
+
+// seasonForDate returns the season that prices a date, from a property's seasons and date price overrides.
+// A date price override wins, then a season limited to certain years, then a recurring season, then the
+// default season; within each group the highest priority wins, then the most recently created.
+// An override is returned as a season; nil means no season covers the date.
+func seasonForDate(seasons []models.Season, overrides []models.DatePriceOverride, date time.Time) *models.Season {
+	if o := matchingOverride(overrides, date.Format("2006-01-02")); o != nil {
+		return &models.Season{ID: o.ID, PropertyID: o.PropertyID, Name: o.Name, DailyPrice: o.DailyPrice}
+	}
+	if matches := matchingSeasons(seasons, date); len(matches) > 0 {
+		return &matches[0]
+	}
+	return defaultSeason(seasons, date)
+}
+
+// matchingOverride returns the most recently created override covering a YYYY-MM-DD date
+func matchingOverride(overrides []models.DatePriceOverride, date string) *models.DatePriceOverride {
+	var match *models.DatePriceOverride
+	for i, o := range overrides {
+		if o.StartDate <= date && date <= o.EndDate && (match == nil || o.CreatedAt.After(match.CreatedAt)) {
+			match = &overrides[i]
+		}
+	}
+	return match
+}
+
+// seasonApplies reports whether a season covers a date. The default season covers every date of its years.
+// A season wrapping the new year belongs to the year it starts in.
+func seasonApplies(s models.Season, date time.Time) bool {
+	monthDay := date.Format("01-02")
+	year := date.Year()
+
+	if s.StartDate <= s.EndDate {
+		if !s.IsDefault && (monthDay < s.StartDate || monthDay > s.EndDate) {
+			return false
+		}
+	} else {
+		if !s.IsDefault && monthDay < s.StartDate && monthDay > s.EndDate {
+			return false
+		}
+		if monthDay <= s.EndDate {
+			year--
+		}
+	}
+
+	if s.StartYear != nil && year < *s.StartYear {
+		return false
+	}
+	if s.EndYear != nil && year > *s.EndYear {
+		return false
+	}
+	return true
+}
+
+// seasonRank orders seasons by kind: seasons limited to certain years before recurring ones, the default last
+func seasonRank(s models.Season) int {
+	switch {
+	case s.IsDefault:
+		return 3
+	case s.StartYear == nil && s.EndYear == nil:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// validateWeekdayAdjustments checks a season's weekday adjustments, returning an error message or ""
+func validateWeekdayAdjustments(adjustments []models.WeekdayAdjustment) string {
+	seen := map[int]bool{}
+	for _, a := range adjustments {
+		if a.Weekday < 0 || a.Weekday > 6 {
+			return "Weekday adjustments must use weekdays from 0 (Sunday) to 6 (Saturday)"
+		}
+		if seen[a.Weekday] {
+			return fmt.Sprintf("%s has more than one weekday adjustment", time.Weekday(a.Weekday))
+		}
+		seen[a.Weekday] = true
+
+		switch a.Type {
+		case models.WeekdayAdjustmentPrice:
+			if a.Amount.Sign() < 0 {
+				return "A weekday price cannot be negative"
+			}
+		case models.WeekdayAdjustmentPercent:
+			if a.Amount.Cmp(-100) <= 0 || a.Amount.Cmp(1000) > 0 {
+				return "A weekday percentage must be above -100 and at most 1000"
+			}
+			if a.Amount.Places() > 2 {
+				return "A weekday percentage can have at most 2 decimals"
+			}
+		default:
+			return "Weekday adjustment type must be price or percent"
+		}
+	}
+	return ""
+}
+
+// isValidMonthDay reports whether s is a real MM-DD date; 02-29 is allowed
+func isValidMonthDay(s string) bool {
+	if !monthDayPattern.MatchString(s) {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", "2024-"+s)
+	return err == nil
+}
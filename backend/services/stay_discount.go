@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// ValidateStayDiscount checks the minimum nights, type and amount of a stay discount, and that
+// the property has no other discount for the same number of nights. The ID is empty for a new discount.
+func ValidateStayDiscount(discount models.StayDiscount) error {
+	if discount.MinNights < 2 {
+		return &ValidationError{"min_nights must be at least 2"}
+	}
+
+	switch discount.Type {
+	case models.StayDiscountPercent:
+		if discount.Amount.Sign() <= 0 || discount.Amount.Cmp(100) >= 0 {
+			return &ValidationError{"A percentage discount must be above 0 and below 100"}
+		}
+		if discount.Amount.Places() > 2 {
+			return &ValidationError{"A percentage can have at most 2 decimals"}
+		}
+	case models.StayDiscountAmount:
+		if discount.Amount.Sign() <= 0 {
+			return &ValidationError{"A discount amount must be above 0"}
+		}
+		if err := ValidatePrice(discount.PropertyID, discount.Amount); err != nil {
+			return err
+		}
+	default:
+		return &ValidationError{"Discount type must be percent or amount"}
+	}
+
+	discounts, err := repository.GetStayDiscountsByPropertyID(discount.PropertyID)
+	if err != nil {
+		return err
+	}
+	for _, d := range discounts {
+		if d.ID != discount.ID && d.MinNights == discount.MinNights {
+			return &ValidationError{fmt.Sprintf("The property already has a discount for %d+ nights (%s)", d.MinNights, d.Name)}
+		}
+	}
+
+	return nil
+}
+
+// applyStayDiscount takes the property's best length-of-stay discount the stay qualifies for off its subtotal.
+// Discounts are tiers, not cumulative: a 30 night stay gets the 28+ night discount only.
+func applyStayDiscount(quote *models.PriceQuote, discounts []models.StayDiscount) {
+	var best *models.StayDiscount
+	for i, d := range discounts {
+		if d.MinNights <= quote.Nights && (best == nil || d.MinNights > best.MinNights) {
+			best = &discounts[i]
+		}
+	}
+	if best == nil {
+		return
+	}
+
+	discount := models.MoneyFromDecimal(best.Amount, quote.Currency)
+	if best.Type == models.StayDiscountPercent {
+		discount = quote.Subtotal.Percent(best.Amount)
+	}
+	discount = discount.Min(quote.Subtotal)
+
+	quote.Discount = discount
+	quote.DiscountName = best.Name
+	quote.Lines = append(quote.Lines, models.QuoteLine{Type: models.QuoteLineDiscount, Name: best.Name, Amount: discount.Neg()})
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"fmt"
+	"time"
+	"villa-arama-riverside/models"
+	"villa-arama-riverside/repository"
+)
+
+// dayRules are the stay rules of one date, and where they come from. The length limits
+// apply to the night starting on the date.
+type dayRules struct {
+	Date              time.Time
+	MinNights         int
+	MinSource         string
+	MaxNights         int
+	MaxSource         string
+	ClosedToArrival   string // source closing the date to arrivals, empty when open
+	ClosedToDeparture string // source closing the date to departures, empty when open
+}
+
+// stayRuleWindowDays is how far ahead stay rules are published in the availability response
+const stayRuleWindowDays = 2 * 365
+
+// maxStayNights is the longest stay accepted when no stay rule sets a lower maximum
+const maxStayNights = 365
+
+// CheckStayRules rejects a stay that arrives or departs on a closed weekday, or that is shorter
+// than the minimum or longer than the maximum of any of its nights. The rules of a date come from
+// the season that prices it and from every stay rule covering it; the strictest wins. Stays are
+// never longer than maxStayNights.
+func CheckStayRules(propertyID string, checkIn, checkOut time.Time) error {
+	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+	if nights < 1 {
+		return nil
+	}
+	if nights > maxStayNights {
+		return &ValidationError{fmt.Sprintf("Stays can be at most %d nights", maxStayNights)}
+	}
+
+	days, err := getDayRules(propertyID, checkIn, checkOut)
+	if err != nil {
+		return err
+	}
+
+	if source := days[0].ClosedToArrival; source != "" {
+		return &ValidationError{fmt.Sprintf("Check-in is not available on %s, %s (%s)",
+			checkIn.Weekday(), checkIn.Format("2006-01-02"), source)}
+	}
+	if source := days[nights].ClosedToDeparture; source != "" {
+		return &ValidationError{fmt.Sprintf("Check-out is not available on %s, %s (%s)",
+			checkOut.Weekday(), checkOut.Format("2006-01-02"), source)}
+	}
+
+	for _, d := range days[:nights] {
+		if d.MinNights > nights {
+			return &ValidationError{fmt.Sprintf("Stays including %s require at least %d nights (%s)",
+				d.Date.Format("2006-01-02"), d.MinNights, d.MinSource)}
+		}
+		if d.MaxNights > 0 && nights > d.MaxNights {
+			return &ValidationError{fmt.Sprintf("Stays including %s can be at most %d nights (%s)",
+				d.Date.Format("2006-01-02"), d.MaxNights, d.MaxSource)}
+		}
+	}
+
+	return nil
+}
+
+// GetStayLimitRanges returns the stay rules of a property from today onwards, as runs of dates
+// with the same rules. Dates without any rule are left out.
+func GetStayLimitRanges(propertyID string) ([]models.StayLimitRange, error) {
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	days, err := getDayRules(propertyID, today, today.AddDate(0, 0, stayRuleWindowDays))
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := []models.StayLimitRange{}
+	for _, d := range days {
+		r := models.StayLimitRange{
+			MinNights:         d.MinNights,
+			MaxNights:         d.MaxNights,
+			ClosedToArrival:   d.ClosedToArrival != "",
+			ClosedToDeparture: d.ClosedToDeparture != "",
+		}
+		if r == (models.StayLimitRange{}) {
+			continue
+		}
+
+		date := d.Date.Format("2006-01-02")
+		if n := len(ranges); n > 0 {
+			last := &ranges[n-1]
+			r.From, r.To = last.From, last.To
+			if *last == r && last.To == d.Date.AddDate(0, 0, -1).Format("2006-01-02") {
+				last.To = date
+				continue
+			}
+		}
+		r.From, r.To = date, date
+		ranges = append(ranges, r)
+	}
+
+	return ranges, nil
+}
+
+// getDayRules returns the stay rules of every date from first to last, inclusive
+func getDayRules(propertyID string, first, last time.Time) ([]dayRules, error) {
+	pricing, err := repository.GetPricingSeasons(propertyID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := repository.GetStayRulesByPropertyID(propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDayRules(pricing.Seasons, rules, first, last), nil
+}
+
+// buildDayRules works out the stay rules of every date from first to last, inclusive,
+// from a property's seasons and stay rules
+func buildDayRules(seasons []models.Season, rules []models.StayRule, first, last time.Time) []dayRules {
+	var days []dayRules
+	for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+		d := dayRules{Date: date}
+
+		season := defaultSeason(seasons, date)
+		if matches := matchingSeasons(seasons, date); len(matches) > 0 {
+			season = &matches[0]
+		}
+		if season != nil {
+			d.tighten(season.MinNights, season.MaxNights, season.Name)
+			d.close(season.ClosedToArrival, season.ClosedToDeparture, season.Name)
+		}
+
+		day := date.Format("2006-01-02")
+		for _, r := range rules {
+			if r.StartDate == nil || (*r.StartDate <= day && day <= *r.EndDate) {
+				d.tighten(r.MinNights, r.MaxNights, r.Name)
+				d.close(r.ClosedToArrival, r.ClosedToDeparture, r.Name)
+			}
+		}
+
+		days = append(days, d)
+	}
+
+	return days
+}
+
+// tighten applies a minimum and maximum to the limits where they are stricter; 0 means no limit
+func (d *dayRules) tighten(minNights, maxNights int, source string) {
+	if minNights > d.MinNights {
+		d.MinNights, d.MinSource = minNights, source
+	}
+	if maxNights > 0 && (d.MaxNights == 0 || maxNights < d.MaxNights) {
+		d.MaxNights, d.MaxSource = maxNights, source
+	}
+}
+
+// close closes the date to arrivals or departures when its weekday is in the given lists
+func (d *dayRules) close(closedToArrival, closedToDeparture []int, source string) {
+	weekday := int(d.Date.Weekday())
+	if d.ClosedToArrival == "" && containsWeekday(closedToArrival, weekday) {
+		d.ClosedToArrival = source
+	}
+	if d.ClosedToDeparture == "" && containsWeekday(closedToDeparture, weekday) {
+		d.ClosedToDeparture = source
+	}
+}
+
+// containsWeekday reports whether a weekday (0 = Sunday) is in a list
+func containsWeekday(days []int, weekday int) bool {
+	for _, d := range days {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateStayRule checks the dates, limits and closed weekdays of a stay rule
+func ValidateStayRule(rule models.StayRule) error {
+	startDate, endDate := rule.StartDate, rule.EndDate
+	if (startDate == nil) != (endDate == nil) {
+		return &ValidationError{"start_date and end_date must be given together, or both left out for a property-wide rule"}
+	}
+	if startDate != nil {
+		start, err := time.Parse("2006-01-02", *startDate)
+		if err != nil {
+			return &ValidationError{"Invalid start_date format, expected YYYY-MM-DD"}
+		}
+		end, err := time.Parse("2006-01-02", *endDate)
+		if err != nil {
+			return &ValidationError{"Invalid end_date format, expected YYYY-MM-DD"}
+		}
+		if end.Before(start) {
+			return &ValidationError{"end_date cannot be before start_date"}
+		}
+	}
+
+	if rule.MinNights == 0 && rule.MaxNights == 0 && len(rule.ClosedToArrival) == 0 && len(rule.ClosedToDeparture) == 0 {
+		return &ValidationError{"Set min_nights, max_nights, closed_to_arrival or closed_to_departure"}
+	}
+	if msg := validateStayLimits(rule.MinNights, rule.MaxNights); msg != "" {
+		return &ValidationError{msg}
+	}
+	if msg := validateClosedWeekdays(rule.ClosedToArrival, rule.ClosedToDeparture); msg != "" {
+		return &ValidationError{msg}
+	}
+	return nil
+}
+
+// validateStayLimits checks a minimum and maximum number of nights, returning an error message or ""
+func validateStayLimits(minNights, maxNights int) string {
+	if minNights < 0 || maxNights < 0 {
+		return "min_nights and max_nights cannot be negative"
+	}
+	if maxNights > 0 && minNights > maxNights {
+		return "min_nights cannot be greater than max_nights"
+	}
+	return ""
+}
+
+// validateClosedWeekdays checks closed to arrival and departure weekdays, returning an error message or ""
+func validateClosedWeekdays(closedToArrival, closedToDeparture []int) string {
+	for _, d := range append(append([]int{}, closedToArrival...), closedToDeparture...) {
+		if d < 0 || d > 6 {
+			return "closed_to_arrival and closed_to_departure must be weekdays from 0 (Sunday) to 6 (Saturday)"
+		}
+	}
+	if len(closedToArrival) == 7 {
+		return "closed_to_arrival cannot close every weekday"
+	}
+	if len(closedToDeparture) == 7 {
+		return "closed_to_departure cannot close every weekday"
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// Storage stores uploaded files under slash-separated keys
+type Storage interface {
+	// Put stores the contents of r under key, replacing any existing object
+	Put(key string, r io.Reader, contentType string) error
+	// Delete removes the object under key; deleting a missing object is not an error
+	Delete(key string) error
+	// URL returns the public URL of the object under key
+	URL(key string) string
+}
+
+// Default is the storage used for uploads, set up by Init
+var Default Storage
+
+// Init selects the storage backend from STORAGE_BACKEND. Only "local" (the default) is built in;
+// other backends implement Storage and are assigned to Default.
+func Init() error {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		dir := os.Getenv("UPLOAD_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		baseURL := os.Getenv("UPLOAD_BASE_URL")
+		if baseURL == "" {
+			baseURL = "/uploads"
+		}
+
+		local, err := NewLocal(dir, baseURL)
+		if err != nil {
+			return err
+		}
+		Default = local
+		return nil
+	default:
+		return errors.New("unknown STORAGE_BACKEND " + backend)
+	}
+}
@@ -1,28 +1,44 @@
 'use client';
 
 import { useState, useEffect } from 'react';
-import { getBedroomConfigs, createBedroomConfig, updateBedroomConfig, deleteBedroomConfig, BedroomConfig } from '@/lib/api';
+import { getAdminProperties, Property, getBedroomConfigs, createBedroomConfig, updateBedroomConfig, deleteBedroomConfig, BedroomConfig, formatMoney } from '@/lib/api';
 
 export default function BedroomConfigsPage() {
   const [configs, setConfigs] = useState<BedroomConfig[]>([]);
+  const [properties, setProperties] = useState<Property[]>([]);
+  const [propertyId, setPropertyId] = useState('');
   const [loading, setLoading] = useState(true);
   const [showModal, setShowModal] = useState(false);
   const [editingConfig, setEditingConfig] = useState<BedroomConfig | null>(null);
+  const currency = properties.find((p) => p.id === propertyId)?.currency || 'USD';
   const [formData, setFormData] = useState({
     name: '',
     description: '',
-    price_add: 0,
+    price_add: '0',
     max_guests: 2,
     is_default: false,
   });
 
   useEffect(() => {
-    loadConfigs();
+    getAdminProperties()
+      .then((data) => {
+        setProperties(data || []);
+        if (data && data.length > 0) {
+          setPropertyId(data[0].id);
+        } else {
+          setLoading(false);
+        }
+      })
+      .catch((error) => console.error('Failed to load properties:', error));
   }, []);
 
+  useEffect(() => {
+    if (propertyId) loadConfigs();
+  }, [propertyId]);
+
   async function loadConfigs() {
     try {
-      const data = await getBedroomConfigs();
+      const data = await getBedroomConfigs(propertyId);
       setConfigs(data || []);
     } catch (error) {
       console.error('Failed to load configs:', error);
@@ -46,7 +62,7 @@ export default function BedroomConfigsPage() {
       setFormData({
         name: '',
         description: '',
-        price_add: 0,
+        price_add: '0',
         max_guests: 2,
         is_default: false,
       });
@@ -60,7 +76,7 @@ export default function BedroomConfigsPage() {
       if (editingConfig) {
         await updateBedroomConfig(editingConfig.id, formData);
       } else {
-        await createBedroomConfig(formData);
+        await createBedroomConfig({ ...formData, property_id: propertyId });
       }
       await loadConfigs();
       setShowModal(false);
@@ -90,7 +106,20 @@ export default function BedroomConfigsPage() {
   return (
     <div>
       <div className="flex items-center justify-between mb-8">
-        <h1 className="text-3xl font-bold text-gray-900">Bedroom Configurations</h1>
+        <div className="flex items-center gap-4">
+          <h1 className="text-3xl font-bold text-gray-900">Bedroom Configurations</h1>
+          {properties.length > 1 && (
+            <select
+              value={propertyId}
+              onChange={(e) => setPropertyId(e.target.value)}
+              className="px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500"
+            >
+              {properties.map((p) => (
+                <option key={p.id} value={p.id}>{p.name}</option>
+              ))}
+            </select>
+          )}
+        </div>
         <button
           onClick={() => openModal()}
           className="bg-primary-600 text-white px-6 py-2 rounded-lg hover:bg-primary-700 transition"
@@ -131,7 +160,7 @@ export default function BedroomConfigsPage() {
             <div className="flex items-center justify-between">
               <span className="text-sm text-gray-600">Up to {config.max_guests} guests</span>
               <span className="text-xl font-bold text-primary-600">
-                {config.price_add === 0 ? 'Base' : `+$${config.price_add}`}
+                {Number(config.price_add) === 0 ? 'Base' : `+${formatMoney(config.price_add, currency)}`}
               </span>
             </div>
           </div>
@@ -169,12 +198,13 @@ export default function BedroomConfigsPage() {
               </div>
               <div className="grid grid-cols-2 gap-4">
                 <div>
-                  <label className="block text-sm font-medium text-gray-700 mb-1">Price Add ($)</label>
+                  <label className="block text-sm font-medium text-gray-700 mb-1">Price Add ({currency})</label>
                   <input
                     type="number"
                     value={formData.price_add}
-                    onChange={(e) => setFormData({ ...formData, price_add: Number(e.target.value) })}
+                    onChange={(e) => setFormData({ ...formData, price_add: e.target.value })}
                     min="0"
+                    step="any"
                     className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500"
                   />
                 </div>
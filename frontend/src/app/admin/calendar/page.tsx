@@ -1,13 +1,14 @@
 'use client';
 
 import { useState, useEffect } from 'react';
-import { getICalURLs, addICalURL, deleteICalURL, syncICalFeeds, getProperties, ICalURL, Property } from '@/lib/api';
+import { getICalURLs, addICalURL, deleteICalURL, syncICalFeeds, getAdminProperties, getICalExportURL, rotateICalExportToken, ICalURL, Property } from '@/lib/api';
 
 export default function CalendarPage() {
   const [icalURLs, setICalURLs] = useState<ICalURL[]>([]);
   const [properties, setProperties] = useState<Property[]>([]);
   const [loading, setLoading] = useState(true);
   const [syncing, setSyncing] = useState(false);
+  const [exportURLs, setExportURLs] = useState<Record<string, string>>({});
   const [showModal, setShowModal] = useState(false);
   const [formData, setFormData] = useState({
     property_id: '',
@@ -23,13 +24,15 @@ export default function CalendarPage() {
     try {
       const [urlsData, propertiesData] = await Promise.all([
         getICalURLs(),
-        getProperties(),
+        getAdminProperties(),
       ]);
       setICalURLs(urlsData || []);
       setProperties(propertiesData || []);
       if (propertiesData.length > 0 && !formData.property_id) {
         setFormData(prev => ({ ...prev, property_id: propertiesData[0].id }));
       }
+      const exports = await Promise.all((propertiesData || []).map((p) => getICalExportURL(p.id)));
+      setExportURLs(Object.fromEntries(exports.map((e) => [e.property_id, e.url])));
     } catch (error) {
       console.error('Failed to load data:', error);
     } finally {
@@ -59,6 +62,16 @@ export default function CalendarPage() {
     }
   }
 
+  async function handleRotate(propertyId: string) {
+    if (!confirm('Rotating the link breaks every OTA still using the old one. Continue?')) return;
+    try {
+      const data = await rotateICalExportToken(propertyId);
+      setExportURLs(prev => ({ ...prev, [data.property_id]: data.url }));
+    } catch (error) {
+      console.error('Failed to rotate export link:', error);
+    }
+  }
+
   async function handleSync() {
     setSyncing(true);
     try {
@@ -112,6 +125,34 @@ export default function CalendarPage() {
         </div>
       </div>
 
+      {/* Export Feeds */}
+      <div className="bg-white rounded-xl shadow-sm p-6 mb-6">
+        <h2 className="text-lg font-semibold text-gray-900 mb-4">Export Your Calendar</h2>
+        <p className="text-gray-600 mb-4">
+          Paste this private link into Airbnb, Booking.com, or VRBO so direct bookings block those dates there too.
+        </p>
+        <div className="space-y-3">
+          {properties.map((property) => (
+            <div key={property.id} className="flex items-center gap-4">
+              <span className="font-medium text-gray-900 w-48 truncate">{property.name}</span>
+              <input
+                type="text"
+                readOnly
+                value={exportURLs[property.id] || ''}
+                onFocus={(e) => e.target.select()}
+                className="flex-1 px-4 py-2 border border-gray-300 rounded-lg text-sm text-gray-600 bg-gray-50"
+              />
+              <button
+                onClick={() => handleRotate(property.id)}
+                className="text-sm text-gray-500 hover:text-red-600 transition"
+              >
+                Reset link
+              </button>
+            </div>
+          ))}
+        </div>
+      </div>
+
       {/* iCal URLs List */}
       {icalURLs.length === 0 ? (
         <div className="bg-white rounded-xl shadow-sm p-12 text-center">
@@ -147,6 +188,9 @@ export default function CalendarPage() {
                         Last synced: {new Date(ical.last_sync).toLocaleString()}
                       </p>
                     )}
+                    {ical.status === 'failed' && ical.last_error && (
+                      <p className="text-xs text-red-600 mt-1 max-w-lg">{ical.last_error}</p>
+                    )}
                   </div>
                 </div>
                 <button
@@ -1,7 +1,7 @@
 'use client';
 
 import { useState, useEffect } from 'react';
-import { getEnquiries, updateEnquiryStatus, exportEnquiries, Enquiry } from '@/lib/api';
+import { getEnquiries, updateEnquiryStatus, exportEnquiries, formatMoney, Enquiry } from '@/lib/api';
 
 export default function EnquiriesPage() {
   const [enquiries, setEnquiries] = useState<Enquiry[]>([]);
@@ -141,7 +141,7 @@ export default function EnquiriesPage() {
                   )}
 
                   <div className="flex items-center justify-between">
-                    <p className="text-2xl font-bold text-primary-600">${enquiry.total_price?.toFixed(2)}</p>
+                    <p className="text-2xl font-bold text-primary-600">{formatMoney(enquiry.total_price, enquiry.currency)}</p>
                     <div className="flex gap-2">
                       {enquiry.status === 'pending' && (
                         <>
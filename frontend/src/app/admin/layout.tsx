@@ -1,8 +1,9 @@
 'use client';
 
 import Link from 'next/link';
-import { usePathname } from 'next/navigation';
+import { usePathname, useRouter } from 'next/navigation';
 import { ReactNode } from 'react';
+import { logout } from '@/lib/api';
 
 const navItems = [
   { href: '/admin', label: 'Dashboard', icon: '📊' },
@@ -14,6 +15,19 @@ const navItems = [
 
 export default function AdminLayout({ children }: { children: ReactNode }) {
   const pathname = usePathname();
+  const router = useRouter();
+
+  if (pathname === '/admin/login') {
+    return <>{children}</>;
+  }
+
+  async function handleLogout() {
+    try {
+      await logout();
+    } finally {
+      router.push('/admin/login');
+    }
+  }
 
   return (
     <div className="min-h-screen bg-gray-100">
@@ -45,6 +59,13 @@ export default function AdminLayout({ children }: { children: ReactNode }) {
         </nav>
 
         <div className="absolute bottom-0 left-0 right-0 p-4 border-t border-gray-800">
+          <button
+            onClick={handleLogout}
+            className="flex items-center gap-2 text-gray-400 hover:text-white transition mb-3"
+          >
+            <span>⎋</span>
+            <span>Log out</span>
+          </button>
           <Link 
             href="/" 
             className="flex items-center gap-2 text-gray-400 hover:text-white transition"
@@ -1,25 +1,28 @@
 'use client';
 
 import { useState, useEffect } from 'react';
-import { getEnquiries, getSeasons, getBedroomConfigs, Enquiry, Season, BedroomConfig } from '@/lib/api';
+import { getEnquiries, getSeasons, getBedroomConfigs, getAdminProperties, formatMoney, Enquiry, Season, BedroomConfig } from '@/lib/api';
 
 export default function AdminDashboard() {
   const [enquiries, setEnquiries] = useState<Enquiry[]>([]);
   const [seasons, setSeasons] = useState<Season[]>([]);
   const [bedroomConfigs, setBedroomConfigs] = useState<BedroomConfig[]>([]);
+  const [currencies, setCurrencies] = useState<Record<string, string>>({});
   const [loading, setLoading] = useState(true);
 
   useEffect(() => {
     async function loadData() {
       try {
-        const [enquiriesData, seasonsData, configsData] = await Promise.all([
+        const [enquiriesData, seasonsData, configsData, propertiesData] = await Promise.all([
           getEnquiries(),
           getSeasons(),
           getBedroomConfigs(),
+          getAdminProperties(),
         ]);
         setEnquiries(enquiriesData || []);
         setSeasons(seasonsData || []);
         setBedroomConfigs(configsData || []);
+        setCurrencies(Object.fromEntries((propertiesData || []).map((p) => [p.id, p.currency])));
       } catch (error) {
         console.error('Failed to load data:', error);
       } finally {
@@ -31,9 +34,13 @@ export default function AdminDashboard() {
 
   const pendingEnquiries = enquiries.filter(e => e.status === 'pending').length;
   const confirmedEnquiries = enquiries.filter(e => e.status === 'confirmed').length;
+  // Revenue is totalled per currency, as properties may price in different currencies
   const totalRevenue = enquiries
     .filter(e => e.status === 'confirmed')
-    .reduce((sum, e) => sum + (e.total_price || 0), 0);
+    .reduce<Record<string, number>>((totals, e) => {
+      totals[e.currency] = (totals[e.currency] || 0) + Number(e.total_price);
+      return totals;
+    }, {});
 
   if (loading) {
     return (
@@ -83,7 +90,11 @@ export default function AdminDashboard() {
           <div className="flex items-center justify-between">
             <div>
               <p className="text-sm text-gray-500">Est. Revenue</p>
-              <p className="text-3xl font-bold text-primary-600">${totalRevenue.toFixed(0)}</p>
+              <p className="text-3xl font-bold text-primary-600">
+                {Object.keys(totalRevenue).length === 0
+                  ? formatMoney('0', 'USD')
+                  : Object.entries(totalRevenue).map(([currency, amount]) => formatMoney(String(amount), currency)).join(' + ')}
+              </p>
             </div>
             <span className="text-3xl">💰</span>
           </div>
@@ -102,7 +113,7 @@ export default function AdminDashboard() {
                   <p className="font-medium text-gray-900">{season.name}</p>
                   <p className="text-sm text-gray-500">{season.start_date} - {season.end_date}</p>
                 </div>
-                <p className="text-lg font-bold text-primary-600">${season.daily_price}/night</p>
+                <p className="text-lg font-bold text-primary-600">{formatMoney(season.daily_price, currencies[season.property_id] || 'USD')}/night</p>
               </div>
             ))}
           </div>
@@ -119,7 +130,7 @@ export default function AdminDashboard() {
                   <p className="text-sm text-gray-500">Up to {config.max_guests} guests</p>
                 </div>
                 <p className="text-lg font-bold text-primary-600">
-                  {config.price_add === 0 ? 'Base' : `+$${config.price_add}`}
+                  {Number(config.price_add) === 0 ? 'Base' : `+${formatMoney(config.price_add, currencies[config.property_id] || 'USD')}`}
                 </p>
               </div>
             ))}
@@ -155,7 +166,7 @@ export default function AdminDashboard() {
                       {enquiry.check_in} → {enquiry.check_out}
                     </td>
                     <td className="py-3 px-4 text-sm text-gray-600">{enquiry.guests}</td>
-                    <td className="py-3 px-4 text-sm font-medium text-gray-900">${enquiry.total_price?.toFixed(2)}</td>
+                    <td className="py-3 px-4 text-sm font-medium text-gray-900">{formatMoney(enquiry.total_price, enquiry.currency)}</td>
                     <td className="py-3 px-4">
                       <span className={`inline-block px-2 py-1 text-xs font-medium rounded-full ${
                         enquiry.status === 'confirmed' ? 'bg-green-100 text-green-700' :
@@ -1,28 +1,54 @@
 'use client';
 
 import { useState, useEffect } from 'react';
-import { getSeasons, createSeason, updateSeason, deleteSeason, Season } from '@/lib/api';
+import { getAdminProperties, Property, getSeasons, createSeason, updateSeason, deleteSeason, Season, WeekdayAdjustment, formatMoney } from '@/lib/api';
+
+const WEEKDAYS = ['Sun', 'Mon', 'Tue', 'Wed', 'Thu', 'Fri', 'Sat'];
 
 export default function SeasonsPage() {
   const [seasons, setSeasons] = useState<Season[]>([]);
+  const [properties, setProperties] = useState<Property[]>([]);
+  const [propertyId, setPropertyId] = useState('');
   const [loading, setLoading] = useState(true);
   const [showModal, setShowModal] = useState(false);
   const [editingSeason, setEditingSeason] = useState<Season | null>(null);
+  const currency = properties.find((p) => p.id === propertyId)?.currency || 'USD';
   const [formData, setFormData] = useState({
     name: '',
     start_date: '',
     end_date: '',
-    daily_price: 0,
+    start_year: null as number | null,
+    end_year: null as number | null,
+    priority: 0,
+    min_nights: 0,
+    max_nights: 0,
+    closed_to_arrival: [] as number[],
+    closed_to_departure: [] as number[],
+    weekday_adjustments: [] as WeekdayAdjustment[],
+    daily_price: '0',
     is_default: false,
   });
 
   useEffect(() => {
-    loadSeasons();
+    getAdminProperties()
+      .then((data) => {
+        setProperties(data || []);
+        if (data && data.length > 0) {
+          setPropertyId(data[0].id);
+        } else {
+          setLoading(false);
+        }
+      })
+      .catch((error) => console.error('Failed to load properties:', error));
   }, []);
 
+  useEffect(() => {
+    if (propertyId) loadSeasons();
+  }, [propertyId]);
+
   async function loadSeasons() {
     try {
-      const data = await getSeasons();
+      const data = await getSeasons(propertyId);
       setSeasons(data || []);
     } catch (error) {
       console.error('Failed to load seasons:', error);
@@ -38,6 +64,14 @@ export default function SeasonsPage() {
         name: season.name,
         start_date: season.start_date,
         end_date: season.end_date,
+        start_year: season.start_year,
+        end_year: season.end_year,
+        priority: season.priority,
+        min_nights: season.min_nights,
+        max_nights: season.max_nights,
+        closed_to_arrival: season.closed_to_arrival || [],
+        closed_to_departure: season.closed_to_departure || [],
+        weekday_adjustments: season.weekday_adjustments || [],
         daily_price: season.daily_price,
         is_default: season.is_default,
       });
@@ -47,7 +81,15 @@ export default function SeasonsPage() {
         name: '',
         start_date: '',
         end_date: '',
-        daily_price: 0,
+        start_year: null,
+        end_year: null,
+        priority: 0,
+        min_nights: 0,
+        max_nights: 0,
+        closed_to_arrival: [],
+        closed_to_departure: [],
+        weekday_adjustments: [],
+        daily_price: '0',
         is_default: false,
       });
     }
@@ -60,7 +102,7 @@ export default function SeasonsPage() {
       if (editingSeason) {
         await updateSeason(editingSeason.id, formData);
       } else {
-        await createSeason(formData);
+        await createSeason({ ...formData, property_id: propertyId });
       }
       await loadSeasons();
       setShowModal(false);
@@ -90,7 +132,20 @@ export default function SeasonsPage() {
   return (
     <div>
       <div className="flex items-center justify-between mb-8">
-        <h1 className="text-3xl font-bold text-gray-900">Seasonal Pricing</h1>
+        <div className="flex items-center gap-4">
+          <h1 className="text-3xl font-bold text-gray-900">Seasonal Pricing</h1>
+          {properties.length > 1 && (
+            <select
+              value={propertyId}
+              onChange={(e) => setPropertyId(e.target.value)}
+              className="px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500"
+            >
+              {properties.map((p) => (
+                <option key={p.id} value={p.id}>{p.name}</option>
+              ))}
+            </select>
+          )}
+        </div>
         <button
           onClick={() => openModal()}
           className="bg-primary-600 text-white px-6 py-2 rounded-lg hover:bg-primary-700 transition"
@@ -129,8 +184,11 @@ export default function SeasonsPage() {
             </div>
             <p className="text-sm text-gray-500 mb-4">
               {season.start_date} → {season.end_date}
+              {(season.start_year || season.end_year) && (
+                <span className="ml-2">({season.start_year ?? '…'}–{season.end_year ?? '…'})</span>
+              )}
             </p>
-            <p className="text-3xl font-bold text-primary-600">${season.daily_price}</p>
+            <p className="text-3xl font-bold text-primary-600">{formatMoney(season.daily_price, currency)}</p>
             <p className="text-sm text-gray-500">per night</p>
           </div>
         ))}
@@ -179,17 +237,138 @@ export default function SeasonsPage() {
                   />
                 </div>
               </div>
+              <div className="grid grid-cols-2 gap-4">
+                <div>
+                  <label className="block text-sm font-medium text-gray-700 mb-1">From Year (optional)</label>
+                  <input
+                    type="number"
+                    value={formData.start_year ?? ''}
+                    onChange={(e) => setFormData({ ...formData, start_year: e.target.value ? Number(e.target.value) : null })}
+                    className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500"
+                    placeholder="Every year"
+                  />
+                </div>
+                <div>
+                  <label className="block text-sm font-medium text-gray-700 mb-1">To Year (optional)</label>
+                  <input
+                    type="number"
+                    value={formData.end_year ?? ''}
+                    onChange={(e) => setFormData({ ...formData, end_year: e.target.value ? Number(e.target.value) : null })}
+                    className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500"
+                    placeholder="Every year"
+                  />
+                </div>
+              </div>
+              <div>
+                <label className="block text-sm font-medium text-gray-700 mb-1">Priority</label>
+                <input
+                  type="number"
+                  value={formData.priority}
+                  onChange={(e) => setFormData({ ...formData, priority: Number(e.target.value) })}
+                  className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500"
+                />
+                <p className="text-xs text-gray-500 mt-1">Where seasons overlap, the higher priority wins.</p>
+              </div>
+              <div className="grid grid-cols-2 gap-4">
+                <div>
+                  <label className="block text-sm font-medium text-gray-700 mb-1">Minimum Nights</label>
+                  <input
+                    type="number"
+                    min={0}
+                    value={formData.min_nights}
+                    onChange={(e) => setFormData({ ...formData, min_nights: Number(e.target.value) })}
+                    className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500"
+                  />
+                </div>
+                <div>
+                  <label className="block text-sm font-medium text-gray-700 mb-1">Maximum Nights</label>
+                  <input
+                    type="number"
+                    min={0}
+                    value={formData.max_nights}
+                    onChange={(e) => setFormData({ ...formData, max_nights: Number(e.target.value) })}
+                    className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500"
+                  />
+                </div>
+              </div>
+              <p className="text-xs text-gray-500 -mt-2">0 means no limit.</p>
+              {(['closed_to_arrival', 'closed_to_departure'] as const).map((field) => (
+                <div key={field}>
+                  <label className="block text-sm font-medium text-gray-700 mb-1">
+                    {field === 'closed_to_arrival' ? 'No Check-in On' : 'No Check-out On'}
+                  </label>
+                  <div className="flex flex-wrap gap-3">
+                    {WEEKDAYS.map((day, index) => (
+                      <label key={day} className="flex items-center gap-1 text-sm text-gray-700">
+                        <input
+                          type="checkbox"
+                          checked={formData[field].includes(index)}
+                          onChange={(e) => setFormData({
+                            ...formData,
+                            [field]: e.target.checked
+                              ? [...formData[field], index].sort((a, b) => a - b)
+                              : formData[field].filter((d) => d !== index),
+                          })}
+                        />
+                        {day}
+                      </label>
+                    ))}
+                  </div>
+                </div>
+              ))}
               <div>
-                <label className="block text-sm font-medium text-gray-700 mb-1">Daily Price ($)</label>
+                <label className="block text-sm font-medium text-gray-700 mb-1">Daily Price ({currency})</label>
                 <input
                   type="number"
                   value={formData.daily_price}
-                  onChange={(e) => setFormData({ ...formData, daily_price: Number(e.target.value) })}
+                  onChange={(e) => setFormData({ ...formData, daily_price: e.target.value })}
                   required
                   min="0"
+                  step="any"
                   className="w-full px-4 py-2 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500"
                 />
               </div>
+              <div>
+                <label className="block text-sm font-medium text-gray-700 mb-1">Weekday Prices</label>
+                <div className="space-y-2">
+                  {WEEKDAYS.map((day, index) => {
+                    const adjustment = formData.weekday_adjustments.find((a) => a.weekday === index);
+                    const others = formData.weekday_adjustments.filter((a) => a.weekday !== index);
+                    return (
+                      <div key={day} className="flex items-center gap-2 text-sm">
+                        <span className="w-10 text-gray-700">{day}</span>
+                        <select
+                          value={adjustment?.type || ''}
+                          onChange={(e) => setFormData({
+                            ...formData,
+                            weekday_adjustments: e.target.value
+                              ? [...others, { weekday: index, type: e.target.value as WeekdayAdjustment['type'], amount: adjustment?.amount || '0' }]
+                              : others,
+                          })}
+                          className="px-2 py-1 border border-gray-300 rounded-lg"
+                        >
+                          <option value="">Season price</option>
+                          <option value="price">Fixed price ({currency})</option>
+                          <option value="percent">Change (%)</option>
+                        </select>
+                        {adjustment && (
+                          <input
+                            type="number"
+                            value={adjustment.amount}
+                            onChange={(e) => setFormData({
+                              ...formData,
+                              weekday_adjustments: [...others, { ...adjustment, amount: e.target.value }],
+                            })}
+                            step="any"
+                            className="w-28 px-2 py-1 border border-gray-300 rounded-lg"
+                          />
+                        )}
+                      </div>
+                    );
+                  })}
+                </div>
+                <p className="text-xs text-gray-500 mt-1">Applies to nights starting on that day, e.g. +20% on Friday and Saturday.</p>
+              </div>
               <div className="flex items-center gap-2">
                 <input
                   type="checkbox"
@@ -2,7 +2,7 @@
 
 import { useState, useEffect } from 'react';
 import { motion } from 'framer-motion';
-import { Property, BedroomConfig, getProperties, getBedroomConfigs, getPropertyAvailability, getPropertyPricing, createEnquiry, PricingResponse } from '@/lib/api';
+import { Property, BedroomConfig, assetUrl, getProperties, getPropertyBedroomConfigs, getPropertyAvailability, createQuote, createEnquiry, Quote, formatMoney } from '@/lib/api';
 
 // Navigation Component
 function Navigation() {
@@ -189,8 +189,8 @@ function AboutSection({ property }: { property: Property | null }) {
             className="grid grid-cols-2 gap-4"
           >
             {property?.images?.slice(0, 4).map((img, idx) => (
-              <div key={idx} className={`rounded-2xl overflow-hidden shadow-lg ${idx === 0 ? 'row-span-2' : ''}`}>
-                <img src={img} alt={`Villa view ${idx + 1}`} className="w-full h-full object-cover" />
+              <div key={img.id} className={`rounded-2xl overflow-hidden shadow-lg ${idx === 0 ? 'row-span-2' : ''}`}>
+                <img src={assetUrl(img.variants.card || img.url)} alt={img.alt_text || `Villa view ${idx + 1}`} className="w-full h-full object-cover" />
               </div>
             )) || (
               <>
@@ -213,7 +213,7 @@ function AboutSection({ property }: { property: Property | null }) {
 }
 
 // Pricing Section
-function PricingSection({ bedroomConfigs }: { bedroomConfigs: BedroomConfig[] }) {
+function PricingSection({ bedroomConfigs, currency }: { bedroomConfigs: BedroomConfig[]; currency: string }) {
   const [seasons] = useState([
     { name: 'Peak Season', period: 'Dec 15 - Jan 10', price: 350, highlight: true },
     { name: 'High Season', period: 'Jul 1 - Aug 31', price: 280, highlight: false },
@@ -280,7 +280,7 @@ function PricingSection({ bedroomConfigs }: { bedroomConfigs: BedroomConfig[] })
                 <p className="text-sm text-gray-500 mb-3">{config.description}</p>
                 <p className="text-sm text-gray-600 mb-2">Up to {config.max_guests} guests</p>
                 <p className="text-xl font-bold text-primary-600">
-                  {config.price_add === 0 ? 'Base price' : `+$${config.price_add}/night`}
+                  {Number(config.price_add) === 0 ? 'Base price' : `+${formatMoney(config.price_add, currency)}/night`}
                 </p>
               </div>
             )) : (
@@ -388,7 +388,9 @@ function BookingSection({
   const [email, setEmail] = useState('');
   const [phone, setPhone] = useState('');
   const [message, setMessage] = useState('');
-  const [pricing, setPricing] = useState<PricingResponse | null>(null);
+  const [promoInput, setPromoInput] = useState('');
+  const [promoCode, setPromoCode] = useState('');
+  const [pricing, setPricing] = useState<Quote | null>(null);
   const [loading, setLoading] = useState(false);
   const [submitting, setSubmitting] = useState(false);
   const [success, setSuccess] = useState(false);
@@ -397,12 +399,26 @@ function BookingSection({
   useEffect(() => {
     if (checkIn && checkOut && property) {
       setLoading(true);
-      getPropertyPricing(property.id, checkIn, checkOut, bedroomConfigId)
-        .then((data) => setPricing(data as PricingResponse))
-        .catch(() => setPricing(null))
+      // A saved quote holds this price for the enquiry even if prices change meanwhile
+      createQuote(property.id, {
+        check_in: checkIn,
+        check_out: checkOut,
+        guests,
+        bedroom_config_id: bedroomConfigId,
+        promo_code: promoCode,
+      })
+        .then((data) => {
+          setPricing(data);
+          setError('');
+        })
+        .catch((err) => {
+          setPricing(null);
+          // Stay rules (minimum nights, changeover days) and promo codes come back as a message for the guest
+          setError(err instanceof Error ? err.message : '');
+        })
         .finally(() => setLoading(false));
     }
-  }, [checkIn, checkOut, bedroomConfigId, property]);
+  }, [checkIn, checkOut, bedroomConfigId, guests, promoCode, property]);
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
@@ -422,6 +438,8 @@ function BookingSection({
         guests,
         bedroom_config_id: bedroomConfigId,
         message,
+        promo_code: promoCode,
+        quote_id: pricing?.id,
       });
       setSuccess(true);
     } catch (err) {
@@ -454,6 +472,8 @@ function BookingSection({
                 setEmail('');
                 setPhone('');
                 setMessage('');
+                setPromoInput('');
+                setPromoCode('');
               }}
               className="bg-primary-600 text-white px-8 py-3 rounded-full hover:bg-primary-700 transition"
             >
@@ -544,23 +564,54 @@ function BookingSection({
                 <option value="">Select configuration</option>
                 {bedroomConfigs.map((config) => (
                   <option key={config.id} value={config.id}>
-                    {config.name} {config.price_add > 0 ? `(+$${config.price_add}/night)` : ''}
+                    {config.name} {property && Number(config.price_add) > 0 ? `(+${formatMoney(config.price_add, property.currency)}/night)` : ''}
                   </option>
                 ))}
               </select>
             </div>
           </div>
 
+          <div className="mb-6">
+            <label className="block text-sm font-medium text-gray-700 mb-2">Promo Code</label>
+            <div className="flex gap-2">
+              <input
+                type="text"
+                value={promoInput}
+                onChange={(e) => setPromoInput(e.target.value.toUpperCase())}
+                placeholder="Optional"
+                className="flex-1 px-4 py-3 border border-gray-300 rounded-lg focus:ring-2 focus:ring-primary-500 focus:border-transparent"
+              />
+              <button
+                type="button"
+                onClick={() => setPromoCode(promoInput.trim())}
+                className="px-6 py-3 border border-primary-600 text-primary-600 rounded-lg hover:bg-primary-50 transition"
+              >
+                Apply
+              </button>
+            </div>
+          </div>
+
           {/* Pricing Display */}
           {pricing && pricing.total_price && (
             <div className="mb-6 p-6 bg-primary-50 rounded-xl border border-primary-100">
               <div className="flex justify-between items-center">
                 <div>
                   <p className="text-sm text-gray-600">Estimated Total ({pricing.nights} nights)</p>
-                  <p className="text-3xl font-bold text-primary-600">${pricing.total_price.toFixed(2)}</p>
+                  <p className="text-3xl font-bold text-primary-600">{formatMoney(pricing.total_price, pricing.currency)}</p>
+                  <p className="text-xs text-gray-500">
+                    Price held until {new Date(pricing.expires_at).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })}
+                  </p>
+                  <ul className="text-sm text-gray-600 mt-2 space-y-0.5">
+                    {pricing.lines.map((line, index) => (
+                      <li key={index} className={`flex justify-between gap-6 ${line.type === 'discount' ? 'text-green-700' : ''}`}>
+                        <span>{line.name}</span>
+                        <span>{formatMoney(line.amount, pricing.currency)}</span>
+                      </li>
+                    ))}
+                  </ul>
                 </div>
                 <div className="text-right text-sm text-gray-500">
-                  <p>Avg. ${(pricing.total_price / pricing.nights).toFixed(2)}/night</p>
+                  <p>Avg. {formatMoney(String(Number(pricing.total_price) / pricing.nights), pricing.currency)}/night</p>
                 </div>
               </div>
             </div>
@@ -674,17 +725,17 @@ export default function HomePage() {
   useEffect(() => {
     async function loadData() {
       try {
-        const [properties, configs] = await Promise.all([
-          getProperties(),
-          getBedroomConfigs(),
-        ]);
+        const properties = await getProperties();
 
         if (properties.length > 0) {
           setProperty(properties[0]);
-          const availability = await getPropertyAvailability(properties[0].id);
+          const [availability, configs] = await Promise.all([
+            getPropertyAvailability(properties[0].id),
+            getPropertyBedroomConfigs(properties[0].id),
+          ]);
           setBlockedDates(availability.blocked_dates || []);
+          setBedroomConfigs(configs || []);
         }
-        setBedroomConfigs(configs);
       } catch (error) {
         console.error('Failed to load data:', error);
       } finally {
@@ -711,7 +762,7 @@ export default function HomePage() {
       <Navigation />
       <Hero property={property} />
       <AboutSection property={property} />
-      <PricingSection bedroomConfigs={bedroomConfigs} />
+      <PricingSection bedroomConfigs={bedroomConfigs} currency={property?.currency || 'USD'} />
       <AmenitiesSection property={property} />
       <BookingSection property={property} bedroomConfigs={bedroomConfigs} blockedDates={blockedDates} />
       <Footer />
@@ -2,7 +2,19 @@
 
 const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:3001/api';
 
+// Resolves image URLs served by the API (e.g. /uploads/...) against the API host
+export function assetUrl(url: string): string {
+  return url.startsWith('/') ? new URL(API_BASE_URL).origin + url : url;
+}
+
 // Types
+
+// Amounts are exact decimal strings in major units, such as "350.00". The object holding them, or
+// the property they belong to, gives their ISO 4217 currency.
+export function formatMoney(amount: string, currency: string): string {
+  return new Intl.NumberFormat(undefined, { style: 'currency', currency }).format(Number(amount));
+}
+
 export interface Property {
   id: string;
   name: string;
@@ -10,31 +22,83 @@ export interface Property {
   description: string;
   location: string;
   image_url: string;
-  images: string[];
+  images: PropertyImage[];
   amenities: string[];
   max_guests: number;
   bedrooms: number;
   bathrooms: number;
+  status: 'draft' | 'published' | 'archived';
+  currency: string;
   created_at: string;
   updated_at: string;
 }
 
+export interface PropertyImage {
+  id: string;
+  property_id: string;
+  url: string;
+  variants: { thumbnail?: string; card?: string; hero?: string };
+  content_type?: string;
+  width?: number;
+  height?: number;
+  size_bytes?: number;
+  caption: string;
+  alt_text: string;
+  sort_order: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export type PropertyInput = Partial<Omit<Property, 'id' | 'images' | 'created_at' | 'updated_at'>>;
+
+export interface PropertyUpdate extends PropertyInput {
+  add_amenities?: string[];
+  remove_amenities?: string[];
+}
+
 export interface Season {
   id: string;
+  property_id: string;
   name: string;
   start_date: string;
   end_date: string;
-  daily_price: number;
+  start_year: number | null;
+  end_year: number | null;
+  priority: number;
+  min_nights: number;
+  max_nights: number;
+  closed_to_arrival: number[];
+  closed_to_departure: number[];
+  daily_price: string;
   is_default: boolean;
+  weekday_adjustments: WeekdayAdjustment[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface WeekdayAdjustment {
+  weekday: number;
+  type: 'price' | 'percent';
+  amount: string;
+}
+
+export interface DatePriceOverride {
+  id: string;
+  property_id: string;
+  name: string;
+  start_date: string;
+  end_date: string;
+  daily_price: string;
   created_at: string;
   updated_at: string;
 }
 
 export interface BedroomConfig {
   id: string;
+  property_id: string;
   name: string;
   description: string;
-  price_add: number;
+  price_add: string;
   max_guests: number;
   is_default: boolean;
   created_at: string;
@@ -44,23 +108,133 @@ export interface BedroomConfig {
 export interface PropertyPricing {
   property_id: string;
   date: string;
+  currency: string;
   season_name: string;
-  season_daily_price: number;
+  season_daily_price: string;
   bedroom_config_id: string;
   bedroom_name: string;
-  bedroom_price_add: number;
-  total_price: number;
+  bedroom_price_add: string;
+  weekday_adjustment: string;
+  weekday_adjustment_name?: string;
+  rule_adjustments?: RuleAdjustment[];
+  total_price: string;
+}
+
+export interface RuleAdjustment {
+  rule_id: string;
+  name: string;
+  percent: string;
+  amount: string;
 }
 
 export interface PricingResponse {
   property_id: string;
   check_in: string;
   check_out: string;
+  currency: string;
   nights: number;
-  total_price: number;
+  guests: number;
+  subtotal: string;
+  discount: string;
+  discount_name?: string;
+  promo_code?: string;
+  promo_discount: string;
+  fees: string;
+  taxes: string;
+  total_price: string;
+  lines: QuoteLine[];
   breakdown: PropertyPricing[];
 }
 
+export interface QuoteLine {
+  type: 'accommodation' | 'discount' | 'fee' | 'tax';
+  name: string;
+  quantity?: number;
+  unit_price?: string;
+  rate?: string;
+  amount: string;
+}
+
+export type PriceQuote = Omit<PricingResponse, 'property_id' | 'check_in' | 'check_out'>;
+
+export interface Quote extends PricingResponse {
+  id: string;
+  bedroom_config_id?: string;
+  expires_at: string;
+  created_at: string;
+}
+
+export interface PropertyFee {
+  id: string;
+  property_id: string;
+  name: string;
+  type: 'per_stay' | 'per_night' | 'per_guest' | 'per_guest_per_night' | 'percent';
+  amount: string;
+  included_guests: number;
+  taxable: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface StayDiscount {
+  id: string;
+  property_id: string;
+  name: string;
+  min_nights: number;
+  type: 'percent' | 'amount';
+  amount: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface PromoCode {
+  id: string;
+  property_id: string;
+  code: string;
+  name: string;
+  type: 'percent' | 'amount';
+  amount: string;
+  valid_from: string | null;
+  valid_until: string | null;
+  stay_from: string | null;
+  stay_until: string | null;
+  min_nights: number;
+  max_uses: number;
+  max_uses_per_email: number;
+  active: boolean;
+  redemptions: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface PricingRule {
+  id: string;
+  property_id: string;
+  name: string;
+  type: 'lead_time' | 'occupancy' | 'gap';
+  min_days_out: number | null;
+  max_days_out: number | null;
+  min_occupancy: number | null;
+  max_occupancy: number | null;
+  window_days: number;
+  max_gap_nights: number;
+  percent: string;
+  active: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface PromoRedemption {
+  id: string;
+  promo_code_id: string;
+  enquiry_id: string;
+  email: string;
+  amount: string;
+  currency: string;
+  enquiry_status: string;
+  created_at: string;
+}
+
 export interface Enquiry {
   id: string;
   property_id: string;
@@ -72,12 +246,23 @@ export interface Enquiry {
   guests: number;
   bedroom_config_id: string;
   message: string;
-  total_price: number;
+  total_price: string;
+  currency: string;
+  quote?: PriceQuote;
   status: string;
   created_at: string;
   updated_at: string;
 }
 
+export interface AdminUser {
+  id: string;
+  email: string;
+  name: string;
+  role: 'owner' | 'manager' | 'staff';
+  created_at: string;
+  updated_at: string;
+}
+
 export interface ICalURL {
   id: string;
   property_id: string;
@@ -85,20 +270,40 @@ export interface ICalURL {
   source: string;
   last_sync: string;
   status: string;
+  last_error: string;
   created_at: string;
   updated_at: string;
 }
 
+export interface ICalSyncRun {
+  id: string;
+  ical_url_id: string;
+  started_at: string;
+  finished_at: string;
+  outcome: 'success' | 'failed';
+  http_status?: number;
+  error_message: string;
+  events_parsed: number;
+  events_skipped: number;
+  dates_added: number;
+  dates_removed: number;
+}
+
 // API Functions
 async function fetchApi<T>(endpoint: string, options?: RequestInit): Promise<T> {
   const response = await fetch(`${API_BASE_URL}${endpoint}`, {
     ...options,
+    credentials: 'include',
     headers: {
       'Content-Type': 'application/json',
       ...options?.headers,
     },
   });
 
+  if (response.status === 401 && endpoint.startsWith('/admin') && typeof window !== 'undefined') {
+    window.location.href = '/admin/login';
+  }
+
   if (!response.ok) {
     const error = await response.json().catch(() => ({ error: 'Unknown error' }));
     throw new Error(error.error || 'API request failed');
@@ -116,25 +321,115 @@ export async function getProperty(id: string): Promise<Property> {
   return fetchApi<Property>(`/properties/${id}`);
 }
 
+// Admin: Properties
+export async function getAdminProperties(): Promise<Property[]> {
+  return fetchApi<Property[]>('/admin/properties');
+}
+
+export async function createProperty(data: PropertyInput): Promise<Property> {
+  return fetchApi<Property>('/admin/properties', {
+    method: 'POST',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function updateProperty(id: string, data: PropertyUpdate): Promise<Property> {
+  return fetchApi<Property>(`/admin/properties/${id}`, {
+    method: 'PUT',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function deleteProperty(id: string): Promise<void> {
+  return fetchApi(`/admin/properties/${id}`, { method: 'DELETE' });
+}
+
+export async function getPropertyImages(propertyId: string): Promise<PropertyImage[]> {
+  return fetchApi<PropertyImage[]>(`/admin/properties/${propertyId}/images`);
+}
+
+export async function uploadPropertyImage(propertyId: string, file: File, caption = '', altText = ''): Promise<PropertyImage> {
+  const body = new FormData();
+  body.append('file', file);
+  body.append('caption', caption);
+  body.append('alt_text', altText);
+  const response = await fetch(`${API_BASE_URL}/admin/properties/${propertyId}/images`, {
+    method: 'POST',
+    body,
+    credentials: 'include',
+  });
+  if (!response.ok) {
+    const error = await response.json().catch(() => ({ error: 'Upload failed' }));
+    throw new Error(error.error || 'Upload failed');
+  }
+  return response.json();
+}
+
+export async function updatePropertyImage(propertyId: string, imageId: string, data: { caption?: string; alt_text?: string }): Promise<PropertyImage> {
+  return fetchApi<PropertyImage>(`/admin/properties/${propertyId}/images/${imageId}`, {
+    method: 'PUT',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function reorderPropertyImages(propertyId: string, imageIds: string[]): Promise<PropertyImage[]> {
+  return fetchApi<PropertyImage[]>(`/admin/properties/${propertyId}/images/order`, {
+    method: 'PUT',
+    body: JSON.stringify({ image_ids: imageIds }),
+  });
+}
+
+export async function deletePropertyImage(propertyId: string, imageId: string): Promise<void> {
+  return fetchApi(`/admin/properties/${propertyId}/images/${imageId}`, { method: 'DELETE' });
+}
+
 export async function getPropertyPricing(
   propertyId: string,
   checkIn?: string,
   checkOut?: string,
-  bedroomConfigId?: string
+  bedroomConfigId?: string,
+  guests?: number,
+  promoCode?: string
 ): Promise<PricingResponse | PropertyPricing> {
   const params = new URLSearchParams();
   if (checkIn) params.append('check_in', checkIn);
   if (checkOut) params.append('check_out', checkOut);
   if (bedroomConfigId) params.append('bedroom_config_id', bedroomConfigId);
+  if (guests) params.append('guests', String(guests));
+  if (promoCode) params.append('promo_code', promoCode);
   
   const query = params.toString() ? `?${params.toString()}` : '';
   return fetchApi(`/properties/${propertyId}/pricing${query}`);
 }
 
-export async function getPropertyAvailability(propertyId: string): Promise<{ property_id: string; blocked_dates: string[] }> {
+export async function getPropertyBedroomConfigs(propertyId: string): Promise<BedroomConfig[]> {
+  return fetchApi<BedroomConfig[]>(`/properties/${propertyId}/bedroom-configs`);
+}
+
+export async function getPropertyAvailability(propertyId: string): Promise<{ property_id: string; blocked_dates: string[]; stay_limits: StayLimitRange[] }> {
   return fetchApi(`/properties/${propertyId}/availability`);
 }
 
+export async function getPropertyCalendar(propertyId: string, from: string, to: string, bedroomConfigId?: string): Promise<PropertyCalendar> {
+  const params = new URLSearchParams({ from, to });
+  if (bedroomConfigId) params.append('bedroom_config_id', bedroomConfigId);
+  return fetchApi<PropertyCalendar>(`/properties/${propertyId}/calendar?${params.toString()}`);
+}
+
+// Quotes
+export async function createQuote(propertyId: string, data: {
+  check_in: string;
+  check_out: string;
+  guests: number;
+  bedroom_config_id?: string;
+  promo_code?: string;
+}): Promise<Quote> {
+  return fetchApi<Quote>(`/properties/${propertyId}/quotes`, {
+    method: 'POST',
+    body: JSON.stringify(data),
+  });
+}
+
 // Enquiries
 export async function createEnquiry(data: {
   property_id: string;
@@ -146,6 +441,8 @@ export async function createEnquiry(data: {
   guests: number;
   bedroom_config_id?: string;
   message?: string;
+  promo_code?: string;
+  quote_id?: string;
 }): Promise<Enquiry> {
   return fetchApi<Enquiry>('/enquiries', {
     method: 'POST',
@@ -153,9 +450,26 @@ export async function createEnquiry(data: {
   });
 }
 
+// Auth
+export async function login(email: string, password: string): Promise<{ token: string; expires_at: string; user: AdminUser }> {
+  return fetchApi('/auth/login', {
+    method: 'POST',
+    body: JSON.stringify({ email, password }),
+  });
+}
+
+export async function logout(): Promise<void> {
+  return fetchApi('/auth/logout', { method: 'POST' });
+}
+
+export async function getCurrentUser(): Promise<AdminUser> {
+  return fetchApi<AdminUser>('/auth/me');
+}
+
 // Admin - Seasons
-export async function getSeasons(): Promise<Season[]> {
-  return fetchApi<Season[]>('/admin/seasons');
+export async function getSeasons(propertyId?: string): Promise<Season[]> {
+  const query = propertyId ? `?property_id=${propertyId}` : '';
+  return fetchApi<Season[]>(`/admin/seasons${query}`);
 }
 
 export async function createSeason(data: Omit<Season, 'id' | 'created_at' | 'updated_at'>): Promise<Season> {
@@ -176,9 +490,223 @@ export async function deleteSeason(id: string): Promise<void> {
   return fetchApi(`/admin/seasons/${id}`, { method: 'DELETE' });
 }
 
+export interface StayRule {
+  id: string;
+  property_id: string;
+  name: string;
+  start_date: string | null;
+  end_date: string | null;
+  min_nights: number;
+  max_nights: number;
+  closed_to_arrival: number[];
+  closed_to_departure: number[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface StayLimitRange {
+  from: string;
+  to: string;
+  min_nights: number;
+  max_nights: number;
+  closed_to_arrival: boolean;
+  closed_to_departure: boolean;
+}
+
+export interface CalendarDay {
+  date: string;
+  price: string;
+  season_name: string;
+  available: boolean;
+  min_nights: number;
+  max_nights: number;
+  closed_to_arrival: boolean;
+  closed_to_departure: boolean;
+}
+
+export interface PropertyCalendar {
+  property_id: string;
+  from: string;
+  to: string;
+  currency: string;
+  bedroom_config_id?: string;
+  days: CalendarDay[];
+}
+
+export interface SeasonRef {
+  id: string;
+  name: string;
+}
+
+export interface SeasonOverlapReport {
+  property_id: string;
+  year: number;
+  overlaps: { seasons: [SeasonRef, SeasonRef]; from: string; to: string; winner: SeasonRef }[];
+  days: {
+    date: string;
+    source: 'override' | 'season' | 'default' | 'none';
+    winner: SeasonRef | null;
+    daily_price: string;
+    candidates?: SeasonRef[];
+  }[];
+}
+
+export async function getSeasonOverlaps(propertyId: string, year: number): Promise<SeasonOverlapReport> {
+  return fetchApi<SeasonOverlapReport>(`/admin/seasons/overlaps?property_id=${propertyId}&year=${year}`);
+}
+
+// Admin - Date price overrides
+export async function getDatePriceOverrides(propertyId?: string): Promise<DatePriceOverride[]> {
+  const query = propertyId ? `?property_id=${propertyId}` : '';
+  return fetchApi<DatePriceOverride[]>(`/admin/price-overrides${query}`);
+}
+
+export async function createDatePriceOverride(data: Omit<DatePriceOverride, 'id' | 'created_at' | 'updated_at'>): Promise<DatePriceOverride> {
+  return fetchApi<DatePriceOverride>('/admin/price-overrides', {
+    method: 'POST',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function updateDatePriceOverride(id: string, data: Omit<DatePriceOverride, 'id' | 'property_id' | 'created_at' | 'updated_at'>): Promise<DatePriceOverride> {
+  return fetchApi<DatePriceOverride>(`/admin/price-overrides/${id}`, {
+    method: 'PUT',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function deleteDatePriceOverride(id: string): Promise<void> {
+  return fetchApi(`/admin/price-overrides/${id}`, { method: 'DELETE' });
+}
+
+// Admin - Stay rules
+export async function getStayRules(propertyId?: string): Promise<StayRule[]> {
+  const query = propertyId ? `?property_id=${propertyId}` : '';
+  return fetchApi<StayRule[]>(`/admin/stay-rules${query}`);
+}
+
+export async function createStayRule(data: Omit<StayRule, 'id' | 'created_at' | 'updated_at'>): Promise<StayRule> {
+  return fetchApi<StayRule>('/admin/stay-rules', {
+    method: 'POST',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function updateStayRule(id: string, data: Omit<StayRule, 'id' | 'property_id' | 'created_at' | 'updated_at'>): Promise<StayRule> {
+  return fetchApi<StayRule>(`/admin/stay-rules/${id}`, {
+    method: 'PUT',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function deleteStayRule(id: string): Promise<void> {
+  return fetchApi(`/admin/stay-rules/${id}`, { method: 'DELETE' });
+}
+
+// Admin - Length-of-stay discounts
+export async function getStayDiscounts(propertyId?: string): Promise<StayDiscount[]> {
+  const query = propertyId ? `?property_id=${propertyId}` : '';
+  return fetchApi<StayDiscount[]>(`/admin/stay-discounts${query}`);
+}
+
+export async function createStayDiscount(data: Omit<StayDiscount, 'id' | 'created_at' | 'updated_at'>): Promise<StayDiscount> {
+  return fetchApi<StayDiscount>('/admin/stay-discounts', {
+    method: 'POST',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function updateStayDiscount(id: string, data: Omit<StayDiscount, 'id' | 'property_id' | 'created_at' | 'updated_at'>): Promise<StayDiscount> {
+  return fetchApi<StayDiscount>(`/admin/stay-discounts/${id}`, {
+    method: 'PUT',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function deleteStayDiscount(id: string): Promise<void> {
+  return fetchApi(`/admin/stay-discounts/${id}`, { method: 'DELETE' });
+}
+
+// Admin - Fees and taxes
+export async function getPropertyFees(propertyId?: string): Promise<PropertyFee[]> {
+  const query = propertyId ? `?property_id=${propertyId}` : '';
+  return fetchApi<PropertyFee[]>(`/admin/fees${query}`);
+}
+
+export async function createPropertyFee(data: Omit<PropertyFee, 'id' | 'created_at' | 'updated_at'>): Promise<PropertyFee> {
+  return fetchApi<PropertyFee>('/admin/fees', {
+    method: 'POST',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function updatePropertyFee(id: string, data: Omit<PropertyFee, 'id' | 'property_id' | 'created_at' | 'updated_at'>): Promise<PropertyFee> {
+  return fetchApi<PropertyFee>(`/admin/fees/${id}`, {
+    method: 'PUT',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function deletePropertyFee(id: string): Promise<void> {
+  return fetchApi(`/admin/fees/${id}`, { method: 'DELETE' });
+}
+
+// Admin - Promo codes
+export async function getPromoCodes(propertyId?: string): Promise<PromoCode[]> {
+  const query = propertyId ? `?property_id=${propertyId}` : '';
+  return fetchApi<PromoCode[]>(`/admin/promo-codes${query}`);
+}
+
+export async function createPromoCode(data: Omit<PromoCode, 'id' | 'redemptions' | 'created_at' | 'updated_at'>): Promise<PromoCode> {
+  return fetchApi<PromoCode>('/admin/promo-codes', {
+    method: 'POST',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function updatePromoCode(id: string, data: Omit<PromoCode, 'id' | 'property_id' | 'redemptions' | 'created_at' | 'updated_at'>): Promise<PromoCode> {
+  return fetchApi<PromoCode>(`/admin/promo-codes/${id}`, {
+    method: 'PUT',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function deletePromoCode(id: string): Promise<void> {
+  return fetchApi(`/admin/promo-codes/${id}`, { method: 'DELETE' });
+}
+
+export async function getPromoRedemptions(id: string): Promise<PromoRedemption[]> {
+  return fetchApi<PromoRedemption[]>(`/admin/promo-codes/${id}/redemptions`);
+}
+
+// Admin - Pricing rules
+export async function getPricingRules(propertyId?: string): Promise<PricingRule[]> {
+  const query = propertyId ? `?property_id=${propertyId}` : '';
+  return fetchApi<PricingRule[]>(`/admin/pricing-rules${query}`);
+}
+
+export async function createPricingRule(data: Omit<PricingRule, 'id' | 'created_at' | 'updated_at'>): Promise<PricingRule> {
+  return fetchApi<PricingRule>('/admin/pricing-rules', {
+    method: 'POST',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function updatePricingRule(id: string, data: Omit<PricingRule, 'id' | 'property_id' | 'created_at' | 'updated_at'>): Promise<PricingRule> {
+  return fetchApi<PricingRule>(`/admin/pricing-rules/${id}`, {
+    method: 'PUT',
+    body: JSON.stringify(data),
+  });
+}
+
+export async function deletePricingRule(id: string): Promise<void> {
+  return fetchApi(`/admin/pricing-rules/${id}`, { method: 'DELETE' });
+}
+
 // Admin - Bedroom Configs
-export async function getBedroomConfigs(): Promise<BedroomConfig[]> {
-  return fetchApi<BedroomConfig[]>('/admin/bedroom-configs');
+export async function getBedroomConfigs(propertyId?: string): Promise<BedroomConfig[]> {
+  const query = propertyId ? `?property_id=${propertyId}` : '';
+  return fetchApi<BedroomConfig[]>(`/admin/bedroom-configs${query}`);
 }
 
 export async function createBedroomConfig(data: Omit<BedroomConfig, 'id' | 'created_at' | 'updated_at'>): Promise<BedroomConfig> {
@@ -212,7 +740,7 @@ export async function updateEnquiryStatus(id: string, status: string): Promise<E
 }
 
 export async function exportEnquiries(): Promise<Blob> {
-  const response = await fetch(`${API_BASE_URL}/admin/enquiries/export`);
+  const response = await fetch(`${API_BASE_URL}/admin/enquiries/export`, { credentials: 'include' });
   return response.blob();
 }
 
@@ -235,3 +763,15 @@ export async function deleteICalURL(id: string): Promise<void> {
 export async function syncICalFeeds(): Promise<{ message: string; count: number }> {
   return fetchApi('/admin/ical/sync', { method: 'POST' });
 }
+
+export async function getICalSyncHistory(id: string, limit = 50): Promise<{ ical_url: ICalURL; runs: ICalSyncRun[] }> {
+  return fetchApi(`/admin/ical/${id}/history?limit=${limit}`);
+}
+
+export async function getICalExportURL(propertyId: string): Promise<{ property_id: string; url: string }> {
+  return fetchApi(`/admin/properties/${propertyId}/ical-export`);
+}
+
+export async function rotateICalExportToken(propertyId: string): Promise<{ property_id: string; url: string }> {
+  return fetchApi(`/admin/properties/${propertyId}/ical-export/rotate`, { method: 'POST' });
+}